@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/andrskom/jrpc2hh/models"
+)
+
+// BatchCall is one element of batch, Error is filled after batch is done
+type BatchCall struct {
+	Method       string
+	Params       interface{}
+	Result       interface{}
+	Notification bool
+	Error        error
+}
+
+func NewBatchCall(method string, params interface{}, result interface{}) *BatchCall {
+	return &BatchCall{Method: method, Params: params, Result: result}
+}
+
+func NewBatchNotification(method string, params interface{}) *BatchCall {
+	return &BatchCall{Method: method, Params: params, Notification: true}
+}
+
+// Batch sends calls in one request, responses are correlated with calls by id.
+// Returned error is error of the whole batch, errors of calls are set to BatchCall.Error.
+func (c *Client) Batch(ctx context.Context, calls []*BatchCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	reqBodies := make([]*models.RequestBody, 0, len(calls))
+	callsById := make(map[string]*BatchCall)
+	for _, call := range calls {
+		var reqBody *models.RequestBody
+		var err error
+		if call.Notification {
+			reqBody, err = newNotificationBody(call.Method, call.Params)
+		} else {
+			reqBody, err = c.newRequestBody(call.Method, call.Params)
+		}
+		if err != nil {
+			return err
+		}
+		if !call.Notification {
+			callsById[idKey(reqBody.Id)] = call
+		}
+		reqBodies = append(reqBodies, reqBody)
+	}
+
+	if len(callsById) == 0 {
+		return c.do(ctx, reqBodies, nil)
+	}
+	var respBytes json.RawMessage
+	if err := c.do(ctx, reqBodies, &respBytes); err != nil {
+		return err
+	}
+	var respBodies []*models.ResponseBody
+	if err := json.Unmarshal(respBytes, &respBodies); err != nil {
+		// Server replies with single error if it can't handle the batch at all
+		var respBody models.ResponseBody
+		if json.Unmarshal(respBytes, &respBody) == nil && respBody.Error != nil {
+			return respBody.Error
+		}
+		return fmt.Errorf("Can't unmarshal response: %s", err.Error())
+	}
+
+	var batchErr error
+	for _, respBody := range respBodies {
+		call, ok := callsById[idKey(respBody.Id)]
+		if !ok {
+			// Server can't determine id of invalid request
+			if respBody.Error != nil {
+				batchErr = respBody.Error
+			}
+			continue
+		}
+		delete(callsById, idKey(respBody.Id))
+		call.Error = decodeResponse(respBody, call.Result)
+	}
+	for id, call := range callsById {
+		call.Error = fmt.Errorf("No response for request with id %s", id)
+	}
+	return batchErr
+}
+
+func idKey(id *interface{}) string {
+	if id == nil {
+		return "null"
+	}
+	b, _ := json.Marshal(*id)
+	return string(b)
+}
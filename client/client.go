@@ -0,0 +1,129 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/andrskom/jrpc2hh/models"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+)
+
+type Client struct {
+	url        string
+	httpClient *http.Client
+	lastId     uint64
+}
+
+func NewClient(url string) *Client {
+	return &Client{url: url, httpClient: http.DefaultClient}
+}
+
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// Call calls method in format 'Service.Method' and unmarshals result of response to result,
+// error of response is returned as *models.Error
+func (c *Client) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	reqBody, err := c.newRequestBody(method, params)
+	if err != nil {
+		return err
+	}
+	var respBody models.ResponseBody
+	if err := c.do(ctx, reqBody, &respBody); err != nil {
+		return err
+	}
+	if idKey(respBody.Id) != idKey(reqBody.Id) {
+		// Server can't determine id of invalid request
+		if respBody.Id == nil && respBody.Error != nil {
+			return respBody.Error
+		}
+		return fmt.Errorf("Id of response %s doesn't match id of request %s", idKey(respBody.Id), idKey(reqBody.Id))
+	}
+	return decodeResponse(&respBody, result)
+}
+
+// Notify sends request without id, server doesn't reply to it
+func (c *Client) Notify(ctx context.Context, method string, params interface{}) error {
+	reqBody, err := newNotificationBody(method, params)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, reqBody, nil)
+}
+
+func (c *Client) newRequestBody(method string, params interface{}) (*models.RequestBody, error) {
+	reqBody, err := newNotificationBody(method, params)
+	if err != nil {
+		return nil, err
+	}
+	var id interface{} = atomic.AddUint64(&c.lastId, 1)
+	reqBody.Id = &id
+	return reqBody, nil
+}
+
+func newNotificationBody(method string, params interface{}) (*models.RequestBody, error) {
+	reqBody := &models.RequestBody{JsonRpc: "2.0", Method: method}
+	if params != nil {
+		paramsBytes, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("Can't marshal params: %s", err.Error())
+		}
+		rawParams := json.RawMessage(paramsBytes)
+		reqBody.Params = &rawParams
+	}
+	return reqBody, nil
+}
+
+func decodeResponse(respBody *models.ResponseBody, result interface{}) error {
+	if respBody.Error != nil {
+		return respBody.Error
+	}
+	if result != nil && respBody.Result != nil {
+		if err := json.Unmarshal(*respBody.Result, result); err != nil {
+			return fmt.Errorf("Can't unmarshal result: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, reqData interface{}, respData interface{}) error {
+	reqBytes, err := json.Marshal(reqData)
+	if err != nil {
+		return fmt.Errorf("Can't marshal request: %s", err.Error())
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(reqBytes))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Can't read response body: %s", err.Error())
+	}
+	// Nothing is expected in response for notifications
+	if respData == nil {
+		if len(bytes.TrimSpace(respBytes)) != 0 {
+			var respBody models.ResponseBody
+			if err := json.Unmarshal(respBytes, &respBody); err == nil && respBody.Error != nil {
+				return respBody.Error
+			}
+		}
+		return nil
+	}
+	if err := json.Unmarshal(respBytes, respData); err != nil {
+		return fmt.Errorf("Can't unmarshal response with status %d: %s", resp.StatusCode, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/andrskom/jrpc2hh/handler"
+	"github.com/andrskom/jrpc2hh/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type Sum struct{}
+
+type SumArgs struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+func (s *Sum) Call(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error) {
+	switch reqBody.GetMethod() {
+	case "Add":
+		var args SumArgs
+		if err := json.Unmarshal(*reqBody.Params, &args); err != nil {
+			return nil, models.NewError(models.ErrorCodeInvalidParams, err.Error(), nil)
+		}
+		return args.A + args.B, nil
+	default:
+		return nil, models.NewError(models.ErrorCodeMethodNotFound, "Unknown method", nil)
+	}
+}
+
+func newServer() *httptest.Server {
+	h := handlers.NewHandler()
+	h.Register(new(Sum))
+	return httptest.NewServer(h)
+}
+
+func TestClient_Call(t *testing.T) {
+	a := assert.New(t)
+	s := newServer()
+	defer s.Close()
+	c := NewClient(s.URL)
+
+	var res int
+	a.NoError(c.Call(context.Background(), "Sum.Add", SumArgs{1, 2}, &res))
+	a.Equal(3, res)
+
+	err := c.Call(context.Background(), "Sum.Sub", SumArgs{1, 2}, &res)
+	jErr, ok := err.(*models.Error)
+	a.True(ok)
+	a.Equal(models.ErrorCodeMethodNotFound, jErr.Code)
+}
+
+func TestClient_Notify(t *testing.T) {
+	a := assert.New(t)
+	s := newServer()
+	defer s.Close()
+	c := NewClient(s.URL)
+
+	a.NoError(c.Notify(context.Background(), "Sum.Add", SumArgs{1, 2}))
+}
+
+func TestClient_Batch(t *testing.T) {
+	a := assert.New(t)
+	s := newServer()
+	defer s.Close()
+	c := NewClient(s.URL)
+
+	var res1, res2 int
+	calls := []*BatchCall{
+		NewBatchCall("Sum.Add", SumArgs{1, 2}, &res1),
+		NewBatchNotification("Sum.Add", SumArgs{0, 0}),
+		NewBatchCall("Sum.Sub", SumArgs{1, 2}, nil),
+		NewBatchCall("Sum.Add", SumArgs{3, 4}, &res2),
+	}
+	a.NoError(c.Batch(context.Background(), calls))
+	a.NoError(calls[0].Error)
+	a.Equal(3, res1)
+	a.NoError(calls[1].Error)
+	jErr, ok := calls[2].Error.(*models.Error)
+	a.True(ok)
+	a.Equal(models.ErrorCodeMethodNotFound, jErr.Code)
+	a.NoError(calls[3].Error)
+	a.Equal(7, res2)
+
+	a.NoError(c.Batch(context.Background(), []*BatchCall{NewBatchNotification("Sum.Add", SumArgs{0, 0})}))
+}
+
+func newRawServer(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+}
+
+func TestClient_Call_IdMismatch(t *testing.T) {
+	a := assert.New(t)
+	s := newRawServer(`{"jsonrpc":"2.0","result":3,"id":100}`)
+	defer s.Close()
+
+	var res int
+	err := NewClient(s.URL).Call(context.Background(), "Sum.Add", SumArgs{1, 2}, &res)
+	a.Error(err)
+	_, ok := err.(*models.Error)
+	a.False(ok)
+	a.Equal(0, res)
+
+	s = newRawServer(`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`)
+	defer s.Close()
+	err = NewClient(s.URL).Call(context.Background(), "Sum.Add", SumArgs{1, 2}, &res)
+	jErr, ok := err.(*models.Error)
+	a.True(ok)
+	a.Equal(models.ErrorCodeInvalidRequest, jErr.Code)
+}
+
+func TestClient_Batch_SingleError(t *testing.T) {
+	a := assert.New(t)
+	s := newRawServer(`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`)
+	defer s.Close()
+
+	calls := []*BatchCall{NewBatchCall("Sum.Add", SumArgs{1, 2}, nil)}
+	err := NewClient(s.URL).Batch(context.Background(), calls)
+	jErr, ok := err.(*models.Error)
+	a.True(ok)
+	a.Equal(models.ErrorCodeInvalidRequest, jErr.Code)
+}
@@ -0,0 +1,56 @@
+package diag
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+)
+
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Message)
+}
+
+// Diagnostics collects problems found in sources, so all of them can be reported at once
+type Diagnostics struct {
+	fs   *token.FileSet
+	list []Diagnostic
+}
+
+func NewDiagnostics(fs *token.FileSet) *Diagnostics {
+	return &Diagnostics{fs: fs, list: make([]Diagnostic, 0)}
+}
+
+func (d *Diagnostics) Add(pos token.Pos, format string, args ...interface{}) {
+	d.list = append(d.list, Diagnostic{d.fs.Position(pos), fmt.Sprintf(format, args...)})
+}
+
+func (d *Diagnostics) HasErrors() bool {
+	return len(d.list) != 0
+}
+
+// List returns diagnostics sorted by file and position
+func (d *Diagnostics) List() []Diagnostic {
+	sort.SliceStable(d.list, func(i, j int) bool {
+		a, b := d.list[i].Pos, d.list[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return d.list
+}
+
+func (d *Diagnostics) Report(w io.Writer) {
+	for _, dg := range d.List() {
+		fmt.Fprintln(w, dg.String())
+	}
+}
@@ -0,0 +1,27 @@
+package diag
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnostics_Report(t *testing.T) {
+	a := assert.New(t)
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, "service.go", "package service\n\nfunc A() {}\n\nfunc B() {}\n", 0)
+	a.NoError(err)
+
+	d := NewDiagnostics(fs)
+	a.False(d.HasErrors())
+	d.Add(f.Decls[1].Pos(), "Bad method '%s'", "B")
+	d.Add(f.Decls[0].Pos(), "Bad method '%s'", "A")
+	a.True(d.HasErrors())
+
+	buf := bytes.NewBuffer(nil)
+	d.Report(buf)
+	a.Equal("service.go:3:1: Bad method 'A'\nservice.go:5:1: Bad method 'B'\n", buf.String())
+}
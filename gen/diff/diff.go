@@ -0,0 +1,208 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// context is count of unchanged lines around changes in hunk
+const context = 3
+
+type op struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Unified returns unified diff of a and b, empty string if they are equal
+func Unified(aName string, bName string, a []byte, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+	ops := lineOps(splitLines(a), splitLines(b))
+
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "--- %s\n+++ %s\n", aName, bName)
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		// hunk is extended while changes are closer than 2*context lines
+		hStart := max(start-context, 0)
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*context {
+				break
+			}
+		}
+		hEnd := min(end+context, len(ops))
+
+		aLine, bLine := 1, 1
+		for _, o := range ops[:hStart] {
+			if o.kind != '+' {
+				aLine++
+			}
+			if o.kind != '-' {
+				bLine++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, o := range ops[hStart:hEnd] {
+			if o.kind != '+' {
+				aCount++
+			}
+			if o.kind != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(aLine, aCount), hunkRange(bLine, bCount))
+		for _, o := range ops[hStart:hEnd] {
+			buf.WriteByte(o.kind)
+			buf.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = hEnd
+	}
+	return buf.String()
+}
+
+func hunkRange(line int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", line-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineOps builds shortest edit script of lines by Myers algorithm in linear space,
+// removed lines are placed before added lines in every run of changes
+func lineOps(a []string, b []string) []op {
+	d := &differ{a: a, b: b, ops: make([]op, 0, len(a)+len(b))}
+	d.diff(0, len(a), 0, len(b))
+
+	ops := d.ops
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		end := start
+		for end < len(ops) && ops[end].kind != ' ' {
+			end++
+		}
+		sort.SliceStable(ops[start:end], func(i, j int) bool {
+			return ops[start+i].kind == '-' && ops[start+j].kind == '+'
+		})
+		start = end
+	}
+	return ops
+}
+
+type differ struct {
+	a   []string
+	b   []string
+	ops []op
+}
+
+// diff appends edit script of a[aLo:aHi] and b[bLo:bHi], common prefix and suffix are trimmed
+// and the rest is split by middle snake
+func (d *differ) diff(aLo int, aHi int, bLo int, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, op{' ', d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+		suffix++
+	}
+	switch {
+	case aLo == aHi:
+		for _, l := range d.b[bLo:bHi] {
+			d.ops = append(d.ops, op{'+', l})
+		}
+	case bLo == bHi:
+		for _, l := range d.a[aLo:aHi] {
+			d.ops = append(d.ops, op{'-', l})
+		}
+	default:
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.diff(aLo, x, bLo, y)
+		for _, l := range d.a[x:u] {
+			d.ops = append(d.ops, op{' ', l})
+		}
+		d.diff(u, aHi, v, bHi)
+	}
+	for _, l := range d.a[aHi : aHi+suffix] {
+		d.ops = append(d.ops, op{' ', l})
+	}
+}
+
+// middleSnake returns start and end of snake in the middle of shortest edit script,
+// paths are searched from both ends at the same time, so memory is linear
+func (d *differ) middleSnake(aLo int, aHi int, bLo int, bHi int) (int, int, int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	maxD := (n + m + 1) / 2
+	off := maxD + 1
+	// furthest x on diagonal k = x - y of forward paths and of backward paths in reversed coordinates
+	vf := make([]int, 2*off+1)
+	vb := make([]int, 2*off+1)
+	for e := 0; e <= maxD; e++ {
+		for k := -e; k <= e; k += 2 {
+			x := vf[off+k-1] + 1
+			if k == -e || (k != e && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+			if kr := delta - k; delta%2 != 0 && kr >= -(e-1) && kr <= e-1 && x+vb[off+kr] >= n {
+				return aLo + x0, bLo + y0, aLo + x, bLo + y
+			}
+		}
+		for kr := -e; kr <= e; kr += 2 {
+			x := vb[off+kr-1] + 1
+			if kr == -e || (kr != e && vb[off+kr-1] < vb[off+kr+1]) {
+				x = vb[off+kr+1]
+			}
+			y := x - kr
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			vb[off+kr] = x
+			if k := delta - kr; delta%2 == 0 && k >= -e && k <= e && x+vf[off+k] >= n {
+				return aHi - x, bHi - y, aHi - x0, bHi - y0
+			}
+		}
+	}
+	// unreachable, paths always meet in the middle
+	return aHi, bHi, aHi, bHi
+}
@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("", Unified("a", "b", []byte("x\ny\n"), []byte("x\ny\n")))
+
+	old := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+	new := []byte("1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n12\n13\n")
+	a.Equal(`--- a
++++ b
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`, Unified("a", "b", old, new))
+
+	a.Equal(`--- a
++++ b
+@@ -0,0 +1,2 @@
++x
++y
+`, Unified("a", "b", nil, []byte("x\ny\n")))
+
+	a.Equal(`--- a
++++ b
+@@ -1 +1 @@
+-x
+\ No newline at end of file
++x
+`, Unified("a", "b", []byte("x"), []byte("x\n")))
+}
+
+// lcsLen returns length of longest common subsequence, it is length of unchanged lines of shortest edit script
+func lcsLen(a []string, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs[0][0]
+}
+
+func TestLineOps(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(1))
+	lines := func() []string {
+		l := make([]string, rnd.Intn(20))
+		for i := range l {
+			l[i] = string(rune('a' + rnd.Intn(4)))
+		}
+		return l
+	}
+	for i := 0; i < 1000; i++ {
+		x, y := lines(), lines()
+		var gotA, gotB []string
+		same := 0
+		for _, o := range lineOps(x, y) {
+			if o.kind != '+' {
+				gotA = append(gotA, o.line)
+			}
+			if o.kind != '-' {
+				gotB = append(gotB, o.line)
+			}
+			if o.kind == ' ' {
+				same++
+			}
+		}
+		a.Equal(len(x), len(gotA))
+		a.Equal(strings.Join(x, ""), strings.Join(gotA, ""))
+		a.Equal(strings.Join(y, ""), strings.Join(gotB, ""))
+		a.Equal(lcsLen(x, y), same, x, y)
+	}
+}
+
+func TestUnified_Large(t *testing.T) {
+	a := assert.New(t)
+	buf := bytes.NewBuffer(nil)
+	for i := 0; i < 200000; i++ {
+		fmt.Fprintf(buf, "line %d\n", i)
+	}
+	old := buf.Bytes()
+	new := append([]byte("first\n"), old...)
+	new = append(new, "last\n"...)
+	a.Equal(`--- a
++++ b
+@@ -1,3 +1,4 @@
++first
+ line 0
+ line 1
+ line 2
+@@ -199998,3 +199999,4 @@
+ line 199997
+ line 199998
+ line 199999
++last
+`, Unified("a", "b", old, new))
+}
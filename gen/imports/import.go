@@ -2,19 +2,27 @@ package imports
 
 import (
 	"fmt"
-	"math/rand"
+	"regexp"
+	"sort"
 	"strings"
-	"time"
+	"unicode"
 )
 
 type ImportMap map[string]*string
 
+// reservedAliases are names of packages imported by generated code without alias
+var reservedAliases = []string{"fmt", "http"}
+
+var regExpMajorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
 func NewImportMap() *ImportMap {
 	am := make(ImportMap)
 	tmp := "jModels"
 	am["github.com/andrskom/jrpc2hh/models"] = &tmp
 	tmp1 := "json"
 	am["encoding/json"] = &tmp1
+	tmp2 := "context"
+	am["context"] = &tmp2
 	return &am
 }
 
@@ -24,39 +32,57 @@ func (am *ImportMap) Register(i string) {
 	}
 }
 
+// GenerateAlias sets aliases for registered imports. Alias is name of package derived from path,
+// colliding names get numeric suffix in order of paths, so aliases are the same on every run.
 func (am *ImportMap) GenerateAlias() {
-	crossMap := make(map[string]*bool)
-	for i, _ := range *am {
-		var p string
-		if (*am)[i] != nil {
+	used := make(map[string]bool)
+	for _, a := range reservedAliases {
+		used[a] = true
+	}
+	paths := make([]string, 0, len(*am))
+	for i, a := range *am {
+		if a != nil {
+			used[*a] = true
 			continue
 		}
-		if strings.Contains(i, "/") {
-			path := strings.Split(i, "/")
-			p = path[len(path)-1]
-		} else {
-			p = i
-		}
-		if _, ok := crossMap[p]; !ok {
-			crossMap[p] = nil
-		} else {
-			tmp := fmt.Sprintf("%s_%d", p, randomNumberGenerator().Int())
-			(*am)[i] = &tmp
+		paths = append(paths, i)
+	}
+	sort.Strings(paths)
+	for _, i := range paths {
+		base := baseName(i)
+		alias := base
+		for n := 2; used[alias]; n++ {
+			alias = fmt.Sprintf("%s%d", base, n)
 		}
+		used[alias] = true
+		tmp := alias
+		(*am)[i] = &tmp
 	}
 }
 
 func (am *ImportMap) GetFormattedAlias(name string) string {
 	if (*am)[name] == nil {
-		path := strings.Split(name, "/")
-		return path[len(path)-1]
+		return baseName(name)
 	} else {
 		return *((*am)[name])
 	}
 }
 
-func randomNumberGenerator() *rand.Rand {
-	s1 := rand.NewSource(time.Now().UnixNano())
-	r1 := rand.New(s1)
-	return r1
+// baseName returns last element of path usable as identifier, major version suffix is skipped
+func baseName(path string) string {
+	elements := strings.Split(path, "/")
+	name := elements[len(elements)-1]
+	if len(elements) > 1 && regExpMajorVersion.MatchString(name) {
+		name = elements[len(elements)-2]
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, name)
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "pkg" + name
+	}
+	return name
 }
@@ -12,8 +12,14 @@ func TestNewImportMap(t *testing.T) {
 
 	val, ok := (*iMap)["github.com/andrskom/jrpc2hh/models"]
 	a.True(ok)
-	a.Equal("jModel", *val)
-	a.Len(*iMap, 1)
+	a.Equal("jModels", *val)
+	val, ok = (*iMap)["encoding/json"]
+	a.True(ok)
+	a.Equal("json", *val)
+	val, ok = (*iMap)["context"]
+	a.True(ok)
+	a.Equal("context", *val)
+	a.Len(*iMap, 3)
 }
 
 func TestRegister(t *testing.T) {
@@ -26,27 +32,28 @@ func TestRegister(t *testing.T) {
 	a.Nil(val)
 	val, ok = (*iMap)["github.com/andrskom/jrpc2hh/models"]
 	a.True(ok)
-	a.Equal("jModel", *val)
-	a.Len(*iMap, 2)
+	a.Equal("jModels", *val)
+	a.Len(*iMap, 4)
 }
 
 func TestGenerateAlias(t *testing.T) {
 	a := assert.New(t)
-	iMap := NewImportMap()
-	iMap.Register("imports")
-	iMap.Register("imports/imports")
-	iMap.Register("blah")
-	iMap.GenerateAlias()
-
-	val, ok := (*iMap)["imports"]
-	a.True(ok)
-	a.Nil(val)
-
-	val, ok = (*iMap)["imports/imports"]
-	a.True(ok)
-	a.Regexp("^import_[0-9]+$", *val)
-
-	val, ok = (*iMap)["blah"]
-	a.True(ok)
-	a.Nil(val)
+	for i := 0; i < 10; i++ {
+		iMap := NewImportMap()
+		iMap.Register("imports/imports")
+		iMap.Register("imports")
+		iMap.Register("blah")
+		iMap.Register("github.com/some/json")
+		iMap.Register("github.com/some/go-redis/v8")
+		iMap.Register("github.com/some/http")
+		iMap.GenerateAlias()
+
+		a.Equal("imports", iMap.GetFormattedAlias("imports"))
+		a.Equal("imports2", iMap.GetFormattedAlias("imports/imports"))
+		a.Equal("blah", iMap.GetFormattedAlias("blah"))
+		a.Equal("json2", iMap.GetFormattedAlias("github.com/some/json"))
+		a.Equal("goredis", iMap.GetFormattedAlias("github.com/some/go-redis/v8"))
+		a.Equal("http2", iMap.GetFormattedAlias("github.com/some/http"))
+		a.Equal("jModels", iMap.GetFormattedAlias("github.com/andrskom/jrpc2hh/models"))
+	}
 }
@@ -1,5 +1,7 @@
 package method
 
+import "go/types"
+
 type MethodList map[string][]*Method
 
 func (ml MethodList) Add(typeName string, m *Method) {
@@ -10,24 +12,64 @@ func (ml MethodList) Add(typeName string, m *Method) {
 }
 
 type Method struct {
-	Name   string
-	Args   *Struct
-	Result *Struct
+	Name string
+	// WireName is name of method in requests, Aliases are accepted too
+	WireName        string
+	Aliases         []string
+	Args            *Struct
+	Result          *Struct
 	ArgsWithContext bool
+	// WithContext is true if method accepts context.Context as first param
+	WithContext bool
+	// ReturnResult is true if method returns (R, error) instead of filling res param
+	ReturnResult bool
+	// Params are options of params decoding set in annotation
+	Params Params
+}
+
+// Params configures strictness of params decoding in generated code
+type Params struct {
+	Required              bool
+	DisallowUnknownFields bool
+	RequiredFields        []string
+}
+
+func NewMethod(n string, a *Struct, r *Struct, argsWithContext bool, withContext bool) *Method {
+	return &Method{n, n, nil, a, r, argsWithContext, withContext, false, Params{}}
+}
+
+// WireNames returns name and aliases of method used in requests
+func (m *Method) WireNames() []string {
+	return append([]string{m.WireName}, m.Aliases...)
+}
+
+func (m *Method) SetWireName(n string, aliases []string) {
+	m.WireName = n
+	m.Aliases = aliases
 }
 
-func NewMethod(n string, a *Struct, r *Struct, withContext bool) *Method {
-	return &Method{n, a, r ,withContext}
+func (m *Method) SetParams(p Params) {
+	m.Params = p
+}
+
+func (m *Method) SetReturnResult() {
+	m.ReturnResult = true
 }
 
 type Struct struct {
 	Pack   string
 	Name   string
 	Prefix string
+	// Type is resolved type without pointer, it is used for describing of type
+	Type types.Type
 }
 
 func NewStruct(p string, n string) *Struct {
-	return &Struct{p, n, ""}
+	return &Struct{p, n, "", nil}
+}
+
+func (s *Struct) SetType(t types.Type) {
+	s.Type = t
 }
 
 func (s *Struct) SetPrefix(p string) {
@@ -0,0 +1,364 @@
+package schema
+
+import (
+	"github.com/andrskom/jrpc2hh/gen/method"
+	"github.com/andrskom/jrpc2hh/gen/service"
+	"github.com/andrskom/jrpc2hh/models"
+	"github.com/andrskom/jrpc2hh/openrpc"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const componentsRef = "#/components/schemas/"
+
+// Builder builds json schemas of args and results from their types, named types are described
+// in components with names qualified by name of package
+type Builder struct {
+	separator  string
+	components map[string]*openrpc.Schema
+	// named contains types described by components by name of component
+	named map[string]types.Type
+}
+
+func NewBuilder() *Builder {
+	return &Builder{
+		separator:  ".",
+		components: make(map[string]*openrpc.Schema),
+		named:      make(map[string]types.Type),
+	}
+}
+
+// SetSeparator sets separator of names of service and method
+func (b *Builder) SetSeparator(sep string) {
+	b.separator = sep
+}
+
+// Document builds OpenRPC document with methods of all services sorted by name used in requests
+func (b *Builder) Document(title string, version string, sl service.ServiceList, ml method.MethodList) *openrpc.Document {
+	doc := openrpc.NewDocument(title, version)
+	doc.Methods = append(doc.Methods, b.Methods(sl, ml)...)
+	if len(b.components) != 0 {
+		doc.Components = &openrpc.Components{Schemas: b.components}
+	}
+	return doc
+}
+
+// Methods describes methods of services sorted by name used in requests,
+// builder can be used for services of several packages
+func (b *Builder) Methods(sl service.ServiceList, ml method.MethodList) []*openrpc.Method {
+	sNames := make([]string, 0, len(ml))
+	for sn := range ml {
+		sNames = append(sNames, sn)
+	}
+	sort.Slice(sNames, func(i, j int) bool {
+		return sl.WireName(sNames[i]) < sl.WireName(sNames[j])
+	})
+	methods := make([]*openrpc.Method, 0)
+	for _, sn := range sNames {
+		for _, m := range ml[sn] {
+			methods = append(methods, b.Method(sl.WireName(sn), m))
+		}
+	}
+	return methods
+}
+
+// Service builds document with methods of one service, names of methods don't contain name of service
+func (b *Builder) Service(methods []*method.Method) *openrpc.Document {
+	sb := NewBuilder()
+	sb.SetSeparator(b.separator)
+	doc := openrpc.NewDocument("", "")
+	for _, m := range methods {
+		doc.Methods = append(doc.Methods, sb.Method("", m))
+	}
+	if len(sb.components) != 0 {
+		doc.Components = &openrpc.Components{Schemas: sb.components}
+	}
+	return doc
+}
+
+// Method builds description of method, name of method is prefixed by name of service if it isn't empty
+func (b *Builder) Method(sn string, m *method.Method) *openrpc.Method {
+	name := m.WireName
+	aliases := m.Aliases
+	if sn != "" {
+		name = sn + b.separator + name
+		aliases = make([]string, 0, len(m.Aliases))
+		for _, alias := range m.Aliases {
+			aliases = append(aliases, sn+b.separator+alias)
+		}
+	}
+	om := &openrpc.Method{
+		Name:    name,
+		Aliases: aliases,
+		Params:  make([]*openrpc.ContentDescriptor, 0),
+		Result:  &openrpc.ContentDescriptor{Name: "result", Schema: b.Struct(m.Result)},
+	}
+	if isModel(m.Args, "NilArgs") {
+		return om
+	}
+	// fields of structure are accepted by name and by position in order of declaration
+	if m.Args.Type != nil {
+		if st, ok := m.Args.Type.Underlying().(*types.Struct); ok {
+			om.ParamStructure = "either"
+			names, properties, required := b.fields(st)
+			required = append(required, m.Params.RequiredFields...)
+			for _, name := range names {
+				om.Params = append(om.Params, &openrpc.ContentDescriptor{Name: name, Required: models.Contains(required, name), Schema: properties[name]})
+			}
+			return om
+		}
+	}
+	om.Params = append(om.Params, &openrpc.ContentDescriptor{Name: "params", Schema: b.Struct(m.Args)})
+	return om
+}
+
+// Components returns schemas of named types referenced by built schemas
+func (b *Builder) Components() map[string]*openrpc.Schema {
+	return b.components
+}
+
+// Struct returns schema of args or result type, type which isn't resolved is described by its name
+func (b *Builder) Struct(s *method.Struct) *openrpc.Schema {
+	if isModel(s, "NilResult") {
+		return &openrpc.Schema{Description: "Empty result"}
+	}
+	if s.Type == nil {
+		return &openrpc.Schema{Description: strings.TrimPrefix(s.Pack+"."+s.Name, ".")}
+	}
+	return b.typeSchema(s.Type)
+}
+
+func (b *Builder) typeSchema(t types.Type) *openrpc.Schema {
+	switch t := types.Unalias(t).(type) {
+	case *types.Basic:
+		return basicSchema(t)
+	case *types.Pointer:
+		return b.typeSchema(t.Elem())
+	case *types.Named:
+		return b.namedSchema(t)
+	case *types.Slice:
+		if isByte(t.Elem()) {
+			return &openrpc.Schema{Type: "string", Format: "byte"}
+		}
+		return &openrpc.Schema{Type: "array", Items: b.typeSchema(t.Elem())}
+	case *types.Array:
+		return &openrpc.Schema{Type: "array", Items: b.typeSchema(t.Elem())}
+	case *types.Map:
+		return &openrpc.Schema{Type: "object", AdditionalProperties: b.typeSchema(t.Elem())}
+	case *types.Struct:
+		_, properties, required := b.fields(t)
+		return &openrpc.Schema{Type: "object", Properties: properties, Required: required}
+	}
+	return &openrpc.Schema{}
+}
+
+// namedSchema returns ref to component describing named type
+func (b *Builder) namedSchema(t *types.Named) *openrpc.Schema {
+	obj := t.Obj()
+	if obj.Pkg() == nil {
+		return b.typeSchema(t.Underlying())
+	}
+	if obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+		return &openrpc.Schema{Type: "string", Format: "date-time"}
+	}
+	// types with custom marshalling have unknown structure
+	if implements(t, "MarshalJSON") {
+		return &openrpc.Schema{}
+	}
+	if implements(t, "MarshalText") {
+		return &openrpc.Schema{Type: "string"}
+	}
+	if _, ok := t.Underlying().(*types.Basic); ok {
+		return b.typeSchema(t.Underlying())
+	}
+
+	name := b.componentName(t)
+	if _, ok := b.components[name]; !ok {
+		b.named[name] = t
+		// placeholder protects from infinite recursion of self-referencing types
+		b.components[name] = &openrpc.Schema{}
+		*b.components[name] = *b.typeSchema(t.Underlying())
+	}
+	return &openrpc.Schema{Ref: componentsRef + name}
+}
+
+// componentName qualifies name of type by name of package, by path of package if name of package
+// is already used by another type
+func (b *Builder) componentName(t *types.Named) string {
+	obj := t.Obj()
+	name := obj.Name()
+	if args := t.TypeArgs(); args != nil {
+		list := make([]string, 0, args.Len())
+		for i := 0; i < args.Len(); i++ {
+			list = append(list, types.TypeString(args.At(i), (*types.Package).Name))
+		}
+		name += "[" + strings.Join(list, ",") + "]"
+	}
+	candidates := []string{
+		obj.Pkg().Name() + "." + name,
+		strings.Replace(obj.Pkg().Path(), "/", ".", -1) + "." + name,
+	}
+	for _, c := range candidates {
+		if used, ok := b.named[c]; !ok || types.Identical(used, t) {
+			return c
+		}
+	}
+	for i := 2; ; i++ {
+		c := candidates[1] + strconv.Itoa(i)
+		if used, ok := b.named[c]; !ok || types.Identical(used, t) {
+			return c
+		}
+	}
+}
+
+// fields returns json names of fields in order of declaration, their schemas and names of required fields,
+// fields of embedded structures are promoted like encoding/json does
+func (b *Builder) fields(st *types.Struct) ([]string, map[string]*openrpc.Schema, []string) {
+	names := make([]string, 0)
+	properties := make(map[string]*openrpc.Schema)
+	var required []string
+	add := func(n string, s *openrpc.Schema) {
+		if _, ok := properties[n]; !ok {
+			names = append(names, n)
+		}
+		properties[n] = s
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		tags := reflect.StructTag(st.Tag(i))
+		tag := tags.Get("json")
+		if tag == "-" {
+			continue
+		}
+		tagName := strings.Split(tag, ",")[0]
+		rules, _ := models.ParseRules(tags.Get(models.TagName))
+		isRequired := false
+		for _, r := range rules {
+			isRequired = isRequired || r.Name == models.RuleRequired
+		}
+
+		if f.Embedded() && tagName == "" {
+			ft := types.Unalias(f.Type())
+			p, isPointer := ft.(*types.Pointer)
+			if isPointer {
+				ft = p.Elem()
+			}
+			if est, ok := ft.Underlying().(*types.Struct); ok {
+				// pointer to unexported struct can't be allocated by encoding/json
+				if !isPointer || f.Exported() {
+					eNames, eProperties, eRequired := b.fields(est)
+					for _, n := range eNames {
+						add(n, eProperties[n])
+					}
+					required = append(required, eRequired...)
+				}
+				continue
+			}
+		}
+		if !f.Exported() {
+			continue
+		}
+		n := f.Name()
+		if tagName != "" {
+			n = tagName
+		}
+		add(n, b.withRules(b.typeSchema(f.Type()), rules))
+		if isRequired {
+			required = append(required, n)
+		}
+	}
+	return names, properties, required
+}
+
+// withRules adds constraints of rules from tag of field to schema of field
+func (b *Builder) withRules(s *openrpc.Schema, rules []models.Rule) *openrpc.Schema {
+	t := s.Type
+	if ref, ok := b.components[strings.TrimPrefix(s.Ref, componentsRef)]; ok && s.Ref != "" {
+		t = ref.Type
+	}
+	for _, r := range rules {
+		switch r.Name {
+		case models.RuleMin, models.RuleMax, models.RuleLen:
+			num, _ := strconv.ParseFloat(r.Value, 64)
+			l := int(num)
+			min, max := r.Name != models.RuleMax, r.Name != models.RuleMin
+			switch t {
+			case "integer", "number":
+				if min {
+					s.Minimum = &num
+				}
+				if max {
+					s.Maximum = &num
+				}
+			case "string":
+				if min {
+					s.MinLength = &l
+				}
+				if max {
+					s.MaxLength = &l
+				}
+			case "array":
+				if min {
+					s.MinItems = &l
+				}
+				if max {
+					s.MaxItems = &l
+				}
+			case "object":
+				if min {
+					s.MinProperties = &l
+				}
+				if max {
+					s.MaxProperties = &l
+				}
+			}
+		case models.RuleRegexp:
+			s.Pattern = r.Value
+		case models.RuleEmail, models.RuleUUID:
+			s.Format = r.Name
+		case models.RuleEnum:
+			for _, v := range strings.Split(r.Value, "|") {
+				if num, err := strconv.ParseFloat(v, 64); err == nil && (t == "integer" || t == "number") {
+					s.Enum = append(s.Enum, num)
+				} else {
+					s.Enum = append(s.Enum, v)
+				}
+			}
+		}
+	}
+	return s
+}
+
+// implements returns true if type or pointer to type has method
+func implements(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+func isByte(t types.Type) bool {
+	basic, ok := types.Unalias(t).(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
+
+func basicSchema(t *types.Basic) *openrpc.Schema {
+	info := t.Info()
+	switch {
+	case info&types.IsBoolean != 0:
+		return &openrpc.Schema{Type: "boolean"}
+	case info&types.IsInteger != 0:
+		return &openrpc.Schema{Type: "integer"}
+	case info&types.IsFloat != 0:
+		return &openrpc.Schema{Type: "number"}
+	case info&types.IsString != 0:
+		return &openrpc.Schema{Type: "string"}
+	}
+	return &openrpc.Schema{}
+}
+
+func isModel(s *method.Struct, name string) bool {
+	return s.Pack == "github.com/andrskom/jrpc2hh/models" && s.Name == name
+}
@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/andrskom/jrpc2hh/gen/method"
+	"github.com/andrskom/jrpc2hh/gen/service"
+	"github.com/stretchr/testify/assert"
+)
+
+const src = `package test
+
+type Base struct {
+	Id int64 ` + "`json:\"id\"`" + `
+}
+
+type Args struct {
+	Base
+	Name    string            ` + "`json:\"name,omitempty\" jrpc2hh:\"required\"`" + `
+	Tags    []string          ` + "`json:\"tags\"`" + `
+	Meta    map[string]float64
+	Skip    bool              ` + "`json:\"-\"`" + `
+	private int
+	Next    *Args             ` + "`json:\"next\"`" + `
+}
+`
+
+const otherSrc = `package other
+
+type Args struct {
+	Id   int64  ` + "`json:\"id\" jrpc2hh:\"min=1\"`" + `
+	Kind string ` + "`json:\"kind\" jrpc2hh:\"required\"`" + `
+}
+`
+
+// checkPackage type-checks source of package with given path
+func checkPackage(t *testing.T, path string, src string) *types.Package {
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check(path, fs, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+// newStruct returns struct of named type of package with resolved type
+func newStruct(pkg *types.Package, name string) *method.Struct {
+	s := method.NewStruct(pkg.Path(), name)
+	s.SetType(pkg.Scope().Lookup(name).Type())
+	return s
+}
+
+func TestBuilder_Method(t *testing.T) {
+	a := assert.New(t)
+	b := NewBuilder()
+	m := b.Method("Test", method.NewMethod(
+		"Do",
+		newStruct(checkPackage(t, "some/test", src), "Args"),
+		method.NewStruct("github.com/andrskom/jrpc2hh/models", "NilResult"),
+		false,
+		false))
+
+	a.Equal("Test.Do", m.Name)
+	a.Equal("either", m.ParamStructure)
+	names := make([]string, 0)
+	for _, p := range m.Params {
+		names = append(names, p.Name)
+	}
+	a.Equal([]string{"id", "name", "tags", "Meta", "next"}, names)
+
+	params, err := json.Marshal(m.Params)
+	a.NoError(err)
+	a.JSONEq(`[
+		{"name":"id","schema":{"type":"integer"}},
+		{"name":"name","required":true,"schema":{"type":"string"}},
+		{"name":"tags","schema":{"type":"array","items":{"type":"string"}}},
+		{"name":"Meta","schema":{"type":"object","additionalProperties":{"type":"number"}}},
+		{"name":"next","schema":{"$ref":"#/components/schemas/test.Args"}}
+	]`, string(params))
+	a.Contains(b.Components(), "test.Args")
+	a.Equal([]string{"name"}, b.Components()["test.Args"].Required)
+}
+
+func TestBuilder_Method_OtherPackage(t *testing.T) {
+	a := assert.New(t)
+	b := NewBuilder()
+	b.SetSeparator("/")
+	args := newStruct(checkPackage(t, "some/other", otherSrc), "Args")
+	m := b.Method("Test", method.NewMethod("Do", args, args, false, false))
+
+	a.Equal("Test/Do", m.Name)
+	a.Equal("either", m.ParamStructure)
+	params, err := json.Marshal(m.Params)
+	a.NoError(err)
+	a.JSONEq(`[
+		{"name":"id","schema":{"type":"integer","minimum":1}},
+		{"name":"kind","required":true,"schema":{"type":"string"}}
+	]`, string(params))
+	a.Equal("#/components/schemas/other.Args", m.Result.Schema.Ref)
+	a.Equal([]string{"kind"}, b.Components()["other.Args"].Required)
+}
+
+func TestBuilder_Struct_SameNames(t *testing.T) {
+	a := assert.New(t)
+	b := NewBuilder()
+
+	first := b.Struct(newStruct(checkPackage(t, "first/other", otherSrc), "Args"))
+	second := b.Struct(newStruct(checkPackage(t, "second/other", otherSrc), "Args"))
+	a.Equal("#/components/schemas/other.Args", first.Ref)
+	a.Equal("#/components/schemas/second.other.Args", second.Ref)
+	a.Len(b.Components(), 2)
+}
+
+func TestBuilder_Document_WireNames(t *testing.T) {
+	a := assert.New(t)
+	b := NewBuilder()
+	sl := make(service.ServiceList)
+	a.NoError(sl.Add("Users"))
+	sl["Users"].SetWireName("users")
+	m := method.NewMethod(
+		"GetUser",
+		method.NewStruct("github.com/andrskom/jrpc2hh/models", "NilArgs"),
+		method.NewStruct("github.com/andrskom/jrpc2hh/models", "NilResult"),
+		false,
+		false)
+	m.SetWireName("get_user", []string{"getUser"})
+	ml := make(method.MethodList)
+	ml.Add("Users", m)
+
+	doc := b.Document("test", "1.0.0", sl, ml)
+	a.Len(doc.Methods, 1)
+	a.Equal("users.get_user", doc.Methods[0].Name)
+	a.Equal([]string{"users.getUser"}, doc.Methods[0].Aliases)
+}
+
+func TestBuilder_Struct(t *testing.T) {
+	a := assert.New(t)
+	b := NewBuilder()
+
+	a.Equal("#/components/schemas/test.Base", b.Struct(newStruct(checkPackage(t, "some/test", src), "Base")).Ref)
+	a.Equal("object", b.Components()["test.Base"].Type)
+	a.Equal("some/models.Model", b.Struct(method.NewStruct("some/models", "Model")).Description)
+}
+
+func TestBuilder_Rules(t *testing.T) {
+	a := assert.New(t)
+	pkg := checkPackage(t, "some/test", `package test
+
+type Args struct {
+	Age  int      `+"`json:\"age\" jrpc2hh:\"min=18,max=99\"`"+`
+	Name string   `+"`json:\"name\" jrpc2hh:\"len=3,regexp=^[a-z]+$\"`"+`
+	Tags []string `+"`json:\"tags\" jrpc2hh:\"max=2\"`"+`
+	Kind string   `+"`json:\"kind\" jrpc2hh:\"enum=a|b\"`"+`
+	Id   string   `+"`json:\"id\" jrpc2hh:\"uuid\"`"+`
+}
+`)
+	b := NewBuilder()
+	b.Struct(newStruct(pkg, "Args"))
+
+	data, err := json.Marshal(b.Components()["test.Args"].Properties)
+	a.NoError(err)
+	a.JSONEq(`{
+		"age":{"type":"integer","minimum":18,"maximum":99},
+		"name":{"type":"string","minLength":3,"maxLength":3,"pattern":"^[a-z]+$"},
+		"tags":{"type":"array","items":{"type":"string"},"maxItems":2},
+		"kind":{"type":"string","enum":["a","b"]},
+		"id":{"type":"string","format":"uuid"}
+	}`, string(data))
+}
@@ -1,21 +1,35 @@
 package service
 
-import "log"
+import "fmt"
 
 type ServiceList map[string]*Service
 
-func (sl ServiceList) Add(sName string) {
+func (sl ServiceList) Add(sName string) error {
 	if _, ok := sl[sName]; ok {
-		log.Fatalf("Service with name '%s' already added", sName)
-	} else {
-		sl[sName] = NewService(sName)
+		return fmt.Errorf("Service with name '%s' already added", sName)
 	}
+	sl[sName] = NewService(sName)
+	return nil
+}
+
+// WireName returns name of service in requests, it is name of type if service isn't annotated
+func (sl ServiceList) WireName(sName string) string {
+	if s, ok := sl[sName]; ok {
+		return s.WireName
+	}
+	return sName
 }
 
 type Service struct {
 	Name string
+	// WireName is name of service in requests
+	WireName string
 }
 
 func NewService(n string) *Service {
-	return &Service{n}
+	return &Service{n, n}
+}
+
+func (s *Service) SetWireName(n string) {
+	s.WireName = n
 }
@@ -0,0 +1,55 @@
+package source
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"golang.org/x/tools/go/ast/astutil"
+	"path"
+	"strconv"
+)
+
+// Format removes unused imports from generated code, sorts imports and formats code,
+// error is returned if generated code can't be parsed
+func Format(filename string, src []byte) ([]byte, error) {
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				used[x.Name] = true
+			}
+		}
+		return true
+	})
+	for _, im := range f.Imports {
+		p, err := strconv.Unquote(im.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		name := path.Base(p)
+		alias := ""
+		if im.Name != nil {
+			name = im.Name.Name
+			alias = im.Name.Name
+		}
+		if name == "_" || name == "." || used[name] {
+			continue
+		}
+		astutil.DeleteNamedImport(fs, f, alias, p)
+	}
+	ast.SortImports(fs, f)
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(src)))
+	if err := format.Node(buf, fs, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
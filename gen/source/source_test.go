@@ -0,0 +1,38 @@
+package source
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	a := assert.New(t)
+
+	res, err := Format("jrpc2hh_test.go", []byte(`package test
+import (
+	"fmt"
+	"net/http"
+	json "encoding/json"
+	jModels "github.com/andrskom/jrpc2hh/models"
+	
+)
+func (s *Test) Call(reqBody *jModels.RequestBody, r *http.Request) (interface{}, *jModels.Error) {
+		return nil, nil
+}`))
+	a.NoError(err)
+	a.Equal(`package test
+
+import (
+	jModels "github.com/andrskom/jrpc2hh/models"
+	"net/http"
+)
+
+func (s *Test) Call(reqBody *jModels.RequestBody, r *http.Request) (interface{}, *jModels.Error) {
+	return nil, nil
+}
+`, string(res))
+
+	_, err = Format("jrpc2hh_test.go", []byte("package test\nfunc {"))
+	a.Error(err)
+}
@@ -0,0 +1,29 @@
+package templates
+
+var Client string = `package {{.Package}}
+//------------------------------------------------------------------------------//
+// This file was generated by andrskom/jrpc2hh package. Please don't remove it. //
+//------------------------------------------------------------------------------//
+
+import (
+	{{range $index, $element := .Imports}}{{$element}} "{{$index}}"
+	{{end}}
+)
+
+// {{.Service}}Client calls methods of service {{.Service}} over http
+type {{.Service}}Client struct {
+	c *jClient.Client
+}
+
+func New{{.Service}}Client(c *jClient.Client) *{{.Service}}Client {
+	return &{{.Service}}Client{c}
+}
+{{range $element := .Methods}}
+{{$element}}
+{{end}}`
+
+var ClientMethod string = `func (c *{{.Service}}Client) {{.Method}}(ctx context.Context{{if .ArgsType}}, args {{.ArgsType}}{{end}}) ({{.ResultType}}, error) {
+	var res {{.ResultType}}
+	err := c.c.Call(ctx, "{{.ServiceName}}{{.Separator}}{{.WireName}}", {{if .ArgsType}}args{{else}}nil{{end}}, &res)
+	return res, err
+}`
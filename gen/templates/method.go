@@ -1,10 +1,10 @@
 package templates
 
-var Method string = `case "{{.Method}}":
+var Method string = `case {{.Names}}:
 		{{.ArgsBlock}}
-		{{.ResultBlock}}
-		err := s.{{.Method}}(args, &res)
+		{{if .ReturnResult}}res, err := s.{{.Method}}({{if .WithContext}}r.Context(), {{end}}{{if .ArgsPointer}}&{{end}}args){{else}}{{.ResultBlock}}
+		err := s.{{.Method}}({{if .WithContext}}r.Context(), {{end}}args, &res){{end}}
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil`
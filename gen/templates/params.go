@@ -6,9 +6,9 @@ var ArgsEmpty string = `if reqBody.HasParams() {
 		var args jModels.NilArgs`
 
 var Args string = `var args %s
-		if reqBody.HasParams() {
-			err := json.Unmarshal(*reqBody.Params, &args)
-			if err != nil {
-				return nil, jModels.NewError(jModels.ErrorCodeInvalidParams, "Can't unmarshal params to args structure'", err.Error())
-			}
+		if jErr := jModels.DecodeParams(reqBody.Params, &args, %s); jErr != nil {
+			return nil, jErr
+		}
+		if jErr := jModels.ValidatePassedArgs(reqBody.Params, &args); jErr != nil {
+			return nil, jErr
 		}`
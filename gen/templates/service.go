@@ -18,6 +18,20 @@ func (s *{{.Service}}) Call(reqBody *jModels.RequestBody, r *http.Request) (inte
 	switch reqBody.GetMethod() {
 	{{range $element := .Methods}}{{$element}}
 	{{end}}default:
-		return nil, jModels.NewError(jModels.ErrorCodeMethodNotFound, fmt.Sprintf("Unknown method '%s' for service '%s'", reqBody.GetMethod(), "{{.Service}}"), nil)
+		return nil, jModels.NewError(jModels.ErrorCodeMethodNotFound, fmt.Sprintf("Unknown method '%s' for service '%s'", reqBody.GetMethod(), "{{.ServiceName}}"), nil)
 	}
-}`
+}{{if ne .ServiceName .Service}}
+
+// ServiceName returns name of service used in requests
+func (s *{{.Service}}) ServiceName() string {
+	return "{{.ServiceName}}"
+}{{end}}{{if .OpenRPC}}
+
+const openRPC{{.Service}} = {{.OpenRPC}}
+
+// Describe returns OpenRPC description of service, names of methods don't contain name of service
+func (s *{{.Service}}) Describe() *jOpenRPC.Document {
+	var doc jOpenRPC.Document
+	json.Unmarshal([]byte(openRPC{{.Service}}), &doc)
+	return &doc
+}{{end}}`
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/andrskom/jrpc2hh/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// SpecService implements methods used in examples of JSON-RPC 2.0 specification
+type SpecService struct{}
+
+func (ss *SpecService) Call(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error) {
+	switch reqBody.GetMethod() {
+	case "subtract":
+		var byPosition []float64
+		if err := json.Unmarshal(*reqBody.Params, &byPosition); err == nil && len(byPosition) == 2 {
+			return byPosition[0] - byPosition[1], nil
+		}
+		var byName struct {
+			Minuend    float64 `json:"minuend"`
+			Subtrahend float64 `json:"subtrahend"`
+		}
+		if err := json.Unmarshal(*reqBody.Params, &byName); err != nil {
+			return nil, models.NewError(models.ErrorCodeInvalidParams, "Invalid params", nil)
+		}
+		return byName.Minuend - byName.Subtrahend, nil
+	case "sum":
+		var values []float64
+		if err := json.Unmarshal(*reqBody.Params, &values); err != nil {
+			return nil, models.NewError(models.ErrorCodeInvalidParams, "Invalid params", nil)
+		}
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum, nil
+	case "update", "notify_hello", "notify_sum":
+		return nil, nil
+	case "get_data":
+		return []interface{}{"hello", 5}, nil
+	}
+	return nil, models.NewError(models.ErrorCodeMethodNotFound, "Method not found", nil)
+}
+
+// normalizeResponse drops messages and data of errors, they aren't defined by specification
+func normalizeResponse(t *testing.T, body []byte) string {
+	var resp interface{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	drop := func(r interface{}) {
+		if obj, ok := r.(map[string]interface{}); ok {
+			if e, ok := obj["error"].(map[string]interface{}); ok {
+				delete(e, "message")
+				delete(e, "data")
+			}
+		}
+	}
+	if list, ok := resp.([]interface{}); ok {
+		for _, r := range list {
+			drop(r)
+		}
+	} else {
+		drop(resp)
+	}
+	data, _ := json.Marshal(resp)
+	return string(data)
+}
+
+// TestHandler_Conformance checks examples of JSON-RPC 2.0 specification and edge cases of request objects
+func TestHandler_Conformance(t *testing.T) {
+	cases := []struct {
+		name     string
+		request  string
+		response string
+	}{
+		{
+			"positional params",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`,
+			`{"jsonrpc": "2.0", "result": 19, "id": 1}`,
+		},
+		{
+			"positional params in another order",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": [23, 42], "id": 2}`,
+			`{"jsonrpc": "2.0", "result": -19, "id": 2}`,
+		},
+		{
+			"named params",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42}, "id": 3}`,
+			`{"jsonrpc": "2.0", "result": 19, "id": 3}`,
+		},
+		{
+			"named params in another order",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": {"minuend": 42, "subtrahend": 23}, "id": 4}`,
+			`{"jsonrpc": "2.0", "result": 19, "id": 4}`,
+		},
+		{
+			"notification",
+			`{"jsonrpc": "2.0", "method": "update", "params": [1,2,3,4,5]}`,
+			``,
+		},
+		{
+			"notification of non-existent method",
+			`{"jsonrpc": "2.0", "method": "foobar"}`,
+			``,
+		},
+		{
+			"non-existent method",
+			`{"jsonrpc": "2.0", "method": "foobar", "id": "1"}`,
+			`{"jsonrpc": "2.0", "error": {"code": -32601}, "id": "1"}`,
+		},
+		{
+			"invalid JSON",
+			`{"jsonrpc": "2.0", "method": "foobar, "params": "bar", "baz]`,
+			`{"jsonrpc": "2.0", "error": {"code": -32700}, "id": null}`,
+		},
+		{
+			"invalid request object",
+			`{"jsonrpc": "2.0", "method": 1, "params": "bar"}`,
+			`{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+		{
+			"batch with invalid JSON",
+			`[
+				{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"},
+				{"jsonrpc": "2.0", "method"
+			]`,
+			`{"jsonrpc": "2.0", "error": {"code": -32700}, "id": null}`,
+		},
+		{
+			"empty batch",
+			`[]`,
+			`{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+		{
+			"invalid batch",
+			`[1]`,
+			`[{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}]`,
+		},
+		{
+			"invalid batch with several elements",
+			`[1,2,3]`,
+			`[
+				{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}
+			]`,
+		},
+		{
+			"batch",
+			`[
+				{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"},
+				{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]},
+				{"jsonrpc": "2.0", "method": "subtract", "params": [42,23], "id": "2"},
+				{"foo": "boo"},
+				{"jsonrpc": "2.0", "method": "foo.get", "params": {"name": "myself"}, "id": "5"},
+				{"jsonrpc": "2.0", "method": "get_data", "id": "9"}
+			]`,
+			`[
+				{"jsonrpc": "2.0", "result": 7, "id": "1"},
+				{"jsonrpc": "2.0", "result": 19, "id": "2"},
+				{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32601}, "id": "5"},
+				{"jsonrpc": "2.0", "result": ["hello", 5], "id": "9"}
+			]`,
+		},
+		{
+			"batch of notifications",
+			`[
+				{"jsonrpc": "2.0", "method": "notify_sum", "params": [1,2,4]},
+				{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]}
+			]`,
+			``,
+		},
+		{
+			"null result",
+			`{"jsonrpc": "2.0", "method": "update", "id": 1}`,
+			`{"jsonrpc": "2.0", "result": null, "id": 1}`,
+		},
+		{
+			"null id isn't notification",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": null}`,
+			`{"jsonrpc": "2.0", "result": 19, "id": null}`,
+		},
+		{
+			"id of invalid type",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": {"a": 1}}`,
+			`{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+		{
+			"wrong version",
+			`{"jsonrpc": "1.0", "method": "subtract", "params": [42, 23], "id": 1}`,
+			`{"jsonrpc": "2.0", "error": {"code": -32600}, "id": 1}`,
+		},
+		{
+			"missing method",
+			`{"jsonrpc": "2.0", "params": [42, 23], "id": 1}`,
+			`{"jsonrpc": "2.0", "error": {"code": -32600}, "id": 1}`,
+		},
+		{
+			"params of invalid type",
+			`{"jsonrpc": "2.0", "method": "subtract", "params": "bar", "id": 1}`,
+			`{"jsonrpc": "2.0", "error": {"code": -32600}, "id": 1}`,
+		},
+		{
+			"request isn't object",
+			`"subtract"`,
+			`{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+		{
+			"null request",
+			`null`,
+			`{"jsonrpc": "2.0", "error": {"code": -32600}, "id": null}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := assert.New(t)
+			h := NewHandler()
+			a.NoError(h.RegisterRoot(new(SpecService)))
+
+			w := doRequest(h, c.request)
+			if c.response == "" {
+				a.Equal(http.StatusNoContent, w.Code)
+				a.Empty(w.Body.String())
+				return
+			}
+			a.Equal(http.StatusOK, w.Code)
+			a.JSONEq(c.response, normalizeResponse(t, w.Body.Bytes()))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"github.com/andrskom/jrpc2hh/models"
+	"github.com/andrskom/jrpc2hh/openrpc"
+	"net/http"
+	"sort"
+)
+
+// DiscoverMethod is reserved method returning OpenRPC document of registered services
+const DiscoverMethod = "rpc.discover"
+
+// Describer is implemented by services generated with -discover flag
+type Describer interface {
+	Describe() *openrpc.Document
+}
+
+// SetDiscoverInfo sets title and version of api in OpenRPC document
+func (h *Handler) SetDiscoverInfo(title string, version string) {
+	h.discoverInfo = openrpc.Info{Title: title, Version: version}
+}
+
+// SetDiscoverPath enables serving of OpenRPC document on GET request with the path,
+// global interceptors receive the request as rpc.discover without id
+func (h *Handler) SetDiscoverPath(path string) {
+	h.discoverPath = path
+}
+
+// discover is call of rpc.discover wrapped by global interceptors, so they can protect description of api
+func (h *Handler) discover(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error) {
+	return h.chainGlobal(func(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error) {
+		return h.Discover(), nil
+	})(reqBody, r)
+}
+
+// Discover assembles OpenRPC document from all registered services implementing Describer
+func (h *Handler) Discover() *openrpc.Document {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	doc := openrpc.NewDocument(h.discoverInfo.Title, h.discoverInfo.Version)
+	names := make([]string, 0, len(h.sMap))
+	for name := range h.sMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d, ok := h.sMap[name].(Describer)
+		if !ok {
+			continue
+		}
+		sDoc := d.Describe()
+		if sDoc.Components != nil {
+			// components having the same names as different components of other services
+			// are namespaced by name of service until names become unique
+			for doc.AddSchemas(sDoc.Components.Schemas) != nil {
+				sDoc.QualifySchemas(name + h.separator)
+			}
+		}
+		for _, m := range sDoc.Methods {
+			sM := *m
+			sM.Name = h.fullName(name, m.Name)
+			sM.Aliases = nil
+			for _, alias := range m.Aliases {
+				sM.Aliases = append(sM.Aliases, h.fullName(name, alias))
+			}
+			doc.Methods = append(doc.Methods, &sM)
+		}
+	}
+	return doc
+}
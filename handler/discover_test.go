@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/andrskom/jrpc2hh/models"
+	"github.com/andrskom/jrpc2hh/openrpc"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type DescribedService struct {
+	CountService
+}
+
+func (ds *DescribedService) Describe() *openrpc.Document {
+	doc := openrpc.NewDocument("", "")
+	doc.Methods = append(doc.Methods, &openrpc.Method{
+		Name:   "Do",
+		Params: []*openrpc.ContentDescriptor{},
+		Result: &openrpc.ContentDescriptor{Name: "result", Schema: &openrpc.Schema{Ref: "#/components/schemas/Res"}},
+	})
+	doc.Components = &openrpc.Components{Schemas: map[string]*openrpc.Schema{"Res": {Type: "string"}}}
+	return doc
+}
+
+func TestHandler_Discover(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	h.SetDiscoverInfo("Test API", "2.0.0")
+	h.SetDiscoverPath("/openrpc.json")
+	h.RegisterName("first", new(DescribedService))
+	h.RegisterName("second", new(DescribedService))
+	h.Register(new(CountService))
+
+	w := doRequest(h, `{"jsonrpc":"2.0","method":"rpc.discover","id":1}`)
+	a.Equal(http.StatusOK, w.Code)
+	var resp models.ResponseBody
+	a.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	var doc openrpc.Document
+	a.NoError(json.Unmarshal(*resp.Result, &doc))
+	a.Equal(openrpc.Info{Title: "Test API", Version: "2.0.0"}, doc.Info)
+	a.Len(doc.Methods, 2)
+	a.Equal("first.Do", doc.Methods[0].Name)
+	a.Equal("second.Do", doc.Methods[1].Name)
+	a.Contains(doc.Components.Schemas, "Res")
+
+	req := httptest.NewRequest(http.MethodGet, "/openrpc.json", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	a.Equal(http.StatusOK, rec.Code)
+	var getDoc openrpc.Document
+	a.NoError(json.Unmarshal(rec.Body.Bytes(), &getDoc))
+	a.Equal(doc, getDoc)
+}
+
+type OtherDescribedService struct {
+	CountService
+}
+
+func (ds *OtherDescribedService) Describe() *openrpc.Document {
+	doc := openrpc.NewDocument("", "")
+	doc.Methods = append(doc.Methods, &openrpc.Method{
+		Name:   "Do",
+		Params: []*openrpc.ContentDescriptor{{Name: "params", Schema: &openrpc.Schema{Type: "array", Items: &openrpc.Schema{Ref: "#/components/schemas/Res"}}}},
+		Result: &openrpc.ContentDescriptor{Name: "result", Schema: &openrpc.Schema{Ref: "#/components/schemas/Res"}},
+	})
+	doc.Components = &openrpc.Components{Schemas: map[string]*openrpc.Schema{"Res": {Type: "integer"}}}
+	return doc
+}
+
+func TestHandler_Discover_SameComponentNames(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	h.RegisterName("first", new(DescribedService))
+	h.RegisterName("second", new(OtherDescribedService))
+
+	doc := h.Discover()
+	a.Len(doc.Methods, 2)
+	a.Equal("#/components/schemas/Res", doc.Methods[0].Result.Schema.Ref)
+	a.Equal("#/components/schemas/second.Res", doc.Methods[1].Result.Schema.Ref)
+	a.Equal("#/components/schemas/second.Res", doc.Methods[1].Params[0].Schema.Items.Ref)
+	a.Equal("string", doc.Components.Schemas["Res"].Type)
+	a.Equal("integer", doc.Components.Schemas["second.Res"].Type)
+}
+
+func TestHandler_Discover_Interceptors(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	h.SetDiscoverPath("/openrpc.json")
+	h.RegisterName("first", new(DescribedService))
+	methods := make([]string, 0)
+	h.Use(func(reqBody *models.RequestBody, r *http.Request, next CallFunc) (interface{}, *models.Error) {
+		methods = append(methods, reqBody.Method)
+		if r.Header.Get("Authorization") == "" {
+			return nil, models.NewError(-32001, "Unauthorized", nil)
+		}
+		return next(reqBody, r)
+	})
+	serviceIntercepted := false
+	h.UseFor("first", func(reqBody *models.RequestBody, r *http.Request, next CallFunc) (interface{}, *models.Error) {
+		serviceIntercepted = true
+		return next(reqBody, r)
+	})
+
+	w := doRequest(h, `{"jsonrpc":"2.0","method":"rpc.discover","id":1}`)
+	var resp models.ResponseBody
+	a.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	a.NotNil(resp.Error)
+	a.Equal(models.ErrorCode(-32001), resp.Error.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/openrpc.json", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	a.Contains(rec.Body.String(), `"code":-32001`)
+	a.NotContains(rec.Body.String(), `"methods"`)
+
+	req = httptest.NewRequest(http.MethodGet, "/openrpc.json", nil)
+	req.Header.Set("Authorization", "token")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var doc openrpc.Document
+	a.NoError(json.Unmarshal(rec.Body.Bytes(), &doc))
+	a.Len(doc.Methods, 1)
+	a.Equal([]string{DiscoverMethod, DiscoverMethod, DiscoverMethod}, methods)
+	a.False(serviceIntercepted)
+}
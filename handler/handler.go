@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/andrskom/jrpc2hh/models"
+	"github.com/andrskom/jrpc2hh/openrpc"
 	"io/ioutil"
 	"net/http"
 	"reflect"
+	"runtime/debug"
 	"sync"
 )
 
@@ -19,18 +22,50 @@ type Validator interface {
 	Validate(service string, method string, params *json.RawMessage) error
 }
 
+const (
+	// BatchUnlimited processes every element of batch in its own goroutine
+	BatchUnlimited = 0
+	// BatchSequential processes elements of batch one by one
+	BatchSequential = 1
+)
+
 type Handler struct {
-	mu           sync.Mutex
-	sMap         map[string]Caller
-	validator    Validator
-	needValidate bool
+	mu               sync.RWMutex
+	sMap             map[string]Caller
+	validator        Validator
+	needValidate     bool
+	batchConcurrency int
+	interceptors     []Interceptor
+	sInterceptors    map[string][]Interceptor
+	panicHandler     PanicHandler
+	discoverInfo     openrpc.Info
+	discoverPath     string
+	separator        string
+	statusPolicy     StatusPolicy
 }
 
+// PanicHandler receives value and stack of panic recovered while processing request
+type PanicHandler func(reqBody *models.RequestBody, r *http.Request, p interface{}, stack []byte)
+
 func NewHandler() *Handler {
-	return &Handler{sMap: make(map[string]Caller)}
+	return &Handler{
+		sMap:          make(map[string]Caller),
+		sInterceptors: make(map[string][]Interceptor),
+		discoverInfo:  openrpc.Info{Title: "JSON-RPC API", Version: "1.0.0"},
+		separator:     DefaultSeparator,
+		statusPolicy:  StatusAlwaysOK,
+	}
+}
+
+// ServiceNamer is implemented by services having name in requests different from name of type
+type ServiceNamer interface {
+	ServiceName() string
 }
 
 func (h *Handler) Register(c Caller) error {
+	if sn, ok := c.(ServiceNamer); ok {
+		return h.RegisterName(sn.ServiceName(), c)
+	}
 	t := reflect.TypeOf(c)
 	var n string
 	if t.Kind() == reflect.Ptr {
@@ -41,6 +76,8 @@ func (h *Handler) Register(c Caller) error {
 	return h.RegisterName(n, c)
 }
 
+// RegisterName registers service with name, name can contain separators to register service in namespace,
+// e.g. 'billing.invoices', service with empty name handles root-level methods
 func (h *Handler) RegisterName(name string, c Caller) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -51,125 +88,211 @@ func (h *Handler) RegisterName(name string, c Caller) error {
 	return nil
 }
 
-func (h *Handler) getService(sN string) (Caller, *models.Error) {
-	c, ok := h.sMap[sN]
-	if !ok {
-		return nil, models.NewError(
-			models.ErrorCodeMethodNotFound,
-			"Unknown service",
-			map[string]string{"methodName": sN})
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodGet && h.discoverPath != "" && req.URL.Path == h.discoverPath {
+		doc, jErr := h.discover(&models.RequestBody{JsonRpc: "2.0", Method: DiscoverMethod}, req)
+		if jErr != nil {
+			h.jsonResponse(w, models.NewResponseError(jErr, nil), jErr, http.StatusInternalServerError)
+			return
+		}
+		models.JsonResponse(w, doc, http.StatusOK)
+		return
 	}
 
-	return c, nil
-}
-
-func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	jErr := models.ValidateHeaders(req)
 	if jErr != nil {
-		models.JsonResponse(w, models.NewResponseError(jErr, nil), http.StatusBadRequest)
+		h.jsonResponse(w, models.NewResponseError(jErr, nil), jErr, http.StatusBadRequest)
 		return
 	}
 
 	reqBodyBytes, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		jErr := models.NewError(models.ErrorCodeInternalError, "Can't read request body", err.Error())
-		models.JsonResponse(w, models.NewResponseError(jErr, nil), http.StatusInternalServerError)
+		h.jsonResponse(w, models.NewResponseError(jErr, nil), jErr, http.StatusInternalServerError)
 		return
 	}
 	req.Body.Close()
 
-	// If hasn't error, that it is simple request
-	var jReq *models.RequestBody
-	errReq := json.Unmarshal(reqBodyBytes, &jReq)
-	if errReq == nil {
-		rB, httpSt := h.doProcedure(jReq, req)
-		models.JsonResponse(w, rB, httpSt)
+	if !json.Valid(reqBodyBytes) {
+		jErr := models.NewError(models.ErrorCodeParseError, "Can't parse request json to json rpc 2.0 struct", nil)
+		h.jsonResponse(w, models.NewResponseError(jErr, nil), jErr, http.StatusBadRequest)
 		return
 	}
 
-	// If hasn't error, that it is batch request
-	var jReqBatchSlice []json.RawMessage
-	errReqBatch := json.Unmarshal(reqBodyBytes, &jReqBatchSlice)
-	if errReqBatch == nil {
-		jRespBatchSlice := struct {
-			sync.Mutex
-			Slice []*models.ResponseBody
-		}{Slice: make([]*models.ResponseBody, 0)}
+	// Array is batch request
+	if body := bytes.TrimLeft(reqBodyBytes, " \t\r\n"); body[0] == '[' {
+		var jReqBatchSlice []json.RawMessage
+		json.Unmarshal(reqBodyBytes, &jReqBatchSlice)
+		if len(jReqBatchSlice) == 0 {
+			jErr := models.NewError(models.ErrorCodeInvalidRequest, "Batch is empty", nil)
+			h.jsonResponse(w, models.NewResponseError(jErr, nil), jErr, http.StatusBadRequest)
+			return
+		}
+		jRespBatchSlice := h.doBatch(jReqBatchSlice, req)
+		// Batch of notifications only, nothing to reply
+		if len(jRespBatchSlice) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h.jsonResponse(w, jRespBatchSlice, nil, http.StatusOK)
+		return
+	}
+
+	rB, httpSt := h.doMessage(reqBodyBytes, req)
+	if rB == nil {
+		w.WriteHeader(httpSt)
+		return
+	}
+	h.jsonResponse(w, rB, rB.Error, httpSt)
+}
+
+// doBatch keeps order of responses the same as order of requests, notifications are skipped
+func (h *Handler) doBatch(reqMessages []json.RawMessage, r *http.Request) []*models.ResponseBody {
+	results := make([]*models.ResponseBody, len(reqMessages))
+	process := func(i int) {
+		results[i], _ = h.doMessage(reqMessages[i], r)
+	}
+
+	if h.batchConcurrency == BatchSequential {
+		for i := range reqMessages {
+			process(i)
+		}
+	} else {
+		var sem chan struct{}
+		if h.batchConcurrency > 0 {
+			sem = make(chan struct{}, h.batchConcurrency)
+		}
 		wg := sync.WaitGroup{}
-		for _, reqMessage := range jReqBatchSlice {
+		for i := range reqMessages {
+			if sem != nil {
+				sem <- struct{}{}
+			}
 			wg.Add(1)
-			go func(reqMessage json.RawMessage, r *http.Request) {
+			go func(i int) {
 				defer wg.Done()
-				var jReqBatch *models.RequestBody
-				err := json.Unmarshal(reqMessage, &jReqBatch)
-				if err != nil {
-					jRespBatchSlice.Lock()
-					defer jRespBatchSlice.Unlock()
-					jRespBatchSlice.Slice = append(
-						jRespBatchSlice.Slice,
-						models.NewResponseError(
-							models.NewError(
-								models.ErrorCodeInvalidRequest,
-								err.Error(),
-								nil),
-							nil,
-						))
-				} else {
-					rB, _ := h.doProcedure(jReqBatch, r)
-					jRespBatchSlice.Lock()
-					defer jRespBatchSlice.Unlock()
-					jRespBatchSlice.Slice = append(
-						jRespBatchSlice.Slice,
-						rB)
+				if sem != nil {
+					defer func() { <-sem }()
 				}
-			}(reqMessage, req)
+				process(i)
+			}(i)
 		}
 		wg.Wait()
-		models.JsonResponse(w, jRespBatchSlice.Slice, http.StatusOK)
-		return
 	}
 
-	// Else that error
-	models.JsonResponse(
-		w,
-		models.NewError(models.ErrorCodeParseError, "Can't parse request json to json rpc 2.0 struct", nil),
-		http.StatusBadRequest)
-	return
+	jRespBatchSlice := make([]*models.ResponseBody, 0, len(results))
+	for _, rB := range results {
+		if rB != nil {
+			jRespBatchSlice = append(jRespBatchSlice, rB)
+		}
+	}
+	return jRespBatchSlice
+}
+
+// doMessage processes one request of body or batch, json value which isn't request object is invalid request
+func (h *Handler) doMessage(message json.RawMessage, r *http.Request) (*models.ResponseBody, int) {
+	var jReq *models.RequestBody
+	err := json.Unmarshal(message, &jReq)
+	if err != nil || jReq == nil {
+		jErr := models.NewError(models.ErrorCodeInvalidRequest, "Request must be json rpc 2.0 request object", nil)
+		return models.NewResponseError(jErr, nil), http.StatusBadRequest
+	}
+	return h.doProcedure(jReq, r)
 }
 
+// doProcedure returns nil body for notifications, they are executed but never replied
 func (h *Handler) doProcedure(jReq *models.RequestBody, r *http.Request) (*models.ResponseBody, int) {
 	err := jReq.Validate()
 	if err != nil {
-		jErr := models.NewError(models.ErrorCodeParseError, err.Error(), nil)
-		return models.NewResponseError(jErr, jReq.Id), http.StatusBadRequest
+		jErr := models.NewError(models.ErrorCodeInvalidRequest, err.Error(), nil)
+		// id of invalid type can't be sent back
+		var id *interface{}
+		if jReq.HasValidId() {
+			id = jReq.Id
+		}
+		return models.NewResponseError(jErr, id), http.StatusBadRequest
 	}
-	s, mErr := h.getService(jReq.GetService())
+	rB, httpSt := h.safeProcedure(jReq, r)
+	if jReq.IsNotification() {
+		return nil, http.StatusNoContent
+	}
+
+	return rB, httpSt
+}
+
+func (h *Handler) callProcedure(jReq *models.RequestBody, r *http.Request) (*models.ResponseBody, int) {
+	if jReq.Method == DiscoverMethod {
+		doc, jErr := h.discover(jReq, r)
+		if jErr != nil {
+			return models.NewResponseError(jErr, jReq.Id), http.StatusInternalServerError
+		}
+		return resultResponse(doc, jReq.Id)
+	}
+	s, mErr := h.route(jReq)
 	if mErr != nil {
 		return models.NewResponseError(mErr, jReq.Id), http.StatusNotFound
 	}
 	if h.needValidate {
-		err = h.validator.Validate(jReq.GetService(), jReq.GetMethod(), jReq.Params)
+		err := h.validator.Validate(jReq.GetService(), jReq.GetMethod(), jReq.Params)
 		if err != nil {
-			jErr := models.NewError(models.ErrorCodeInvalidParams, "Invalid params", err.Error())
+			// structured errors of validator are sent as is
+			var jErr *models.Error
+			if !errors.As(err, &jErr) {
+				jErr = models.NewError(models.ErrorCodeInvalidParams, "Invalid params", err.Error())
+			}
 			return models.NewResponseError(jErr, jReq.Id), http.StatusInternalServerError
 		}
 	}
-	res, jErr := s.Call(jReq, r)
+	res, jErr := h.chain(jReq.GetService(), s)(jReq, r)
 	if jErr != nil {
 		return models.NewResponseError(jErr, jReq.Id), http.StatusInternalServerError
 	}
 
+	return resultResponse(res, jReq.Id)
+}
+
+func resultResponse(res interface{}, id *interface{}) (*models.ResponseBody, int) {
 	if res == nil {
-		return models.NewResponseBody(nil, jReq.Id), http.StatusOK
+		// successful response must contain result member
+		null := json.RawMessage("null")
+		return models.NewResponseBody(&null, id), http.StatusOK
 	} else {
 		resByte, err := json.Marshal(res)
 		if err != nil {
 			jErr := models.NewError(models.ErrorCodeInternalError, "Can't marshal response", err.Error())
-			return models.NewResponseError(jErr, jReq.Id), http.StatusInternalServerError
+			return models.NewResponseError(jErr, id), http.StatusInternalServerError
 		}
 		jsonRes := json.RawMessage(resByte)
-		return models.NewResponseBody(&jsonRes, jReq.Id), http.StatusOK
+		return models.NewResponseBody(&jsonRes, id), http.StatusOK
+	}
+}
+
+// safeProcedure converts panic of validator, interceptors, service method or marshalling of result
+// to internal error, so panic in one request of batch doesn't crash process
+func (h *Handler) safeProcedure(jReq *models.RequestBody, r *http.Request) (rB *models.ResponseBody, httpSt int) {
+	defer func() {
+		if p := recover(); p != nil {
+			if h.panicHandler != nil {
+				h.panicHandler(jReq, r, p, debug.Stack())
+			}
+			jErr := models.NewError(models.ErrorCodeInternalError, "Internal error", nil)
+			rB, httpSt = models.NewResponseError(jErr, jReq.Id), http.StatusInternalServerError
+		}
+	}()
+	return h.callProcedure(jReq, r)
+}
+
+// SetPanicHandler sets hook for reporting panics recovered while processing requests
+func (h *Handler) SetPanicHandler(panicHandler PanicHandler) {
+	h.panicHandler = panicHandler
+}
+
+// SetBatchConcurrency limits count of batch elements processed in parallel,
+// use BatchSequential for sequential processing and BatchUnlimited for no limit
+func (h *Handler) SetBatchConcurrency(n int) {
+	if n < 0 {
+		n = BatchUnlimited
 	}
+	h.batchConcurrency = n
 }
 
 func (h *Handler) SetValidator(validator Validator) {
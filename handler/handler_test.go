@@ -1,19 +1,48 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"github.com/andrskom/jrpc2hh/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type MockService struct {
 	mock.Mock
 }
 
-func (mc *MockService) Call(reqBody *models.RequestBody) (interface{}, *models.Error) {
-	args := mc.Called(reqBody)
-	return args.Get(0).(interface{}), args.Get(0).(*models.Error)
+func (mc *MockService) Call(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error) {
+	args := mc.Called(reqBody, r)
+	return args.Get(0).(interface{}), args.Get(1).(*models.Error)
+}
+
+type CountService struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (cs *CountService) Call(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.calls = append(cs.calls, reqBody.GetMethod())
+	return models.JsonRpcResultOk, nil
+}
+
+func doRequest(h *Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
 }
 
 func TestHandler_Register(t *testing.T) {
@@ -24,3 +53,193 @@ func TestHandler_Register(t *testing.T) {
 	_, ok := h.sMap["MockService"]
 	a.True(ok)
 }
+
+type NamedService struct {
+	CountService
+}
+
+func (ns *NamedService) ServiceName() string {
+	return "users"
+}
+
+func TestHandler_Register_ServiceNamer(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	s := new(NamedService)
+	a.NoError(h.Register(s))
+	_, ok := h.sMap["users"]
+	a.True(ok)
+
+	w := doRequest(h, `{"jsonrpc":"2.0","method":"users.get_user","id":1}`)
+	a.Equal(http.StatusOK, w.Code)
+	a.Equal([]string{"get_user"}, s.calls)
+}
+
+func TestHandler_ServeHTTP_Notification(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	s := new(CountService)
+	h.Register(s)
+
+	w := doRequest(h, `{"jsonrpc":"2.0","method":"CountService.Do"}`)
+	a.Equal(http.StatusNoContent, w.Code)
+	a.Empty(w.Body.String())
+	a.Equal([]string{"Do"}, s.calls)
+}
+
+func TestHandler_ServeHTTP_BatchWithNotifications(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	s := new(CountService)
+	h.Register(s)
+
+	w := doRequest(h, `[{"jsonrpc":"2.0","method":"CountService.Do"},{"jsonrpc":"2.0","method":"CountService.Do","id":1}]`)
+	a.Equal(http.StatusOK, w.Code)
+	var resp []*models.ResponseBody
+	a.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	a.Len(resp, 1)
+	a.Equal(float64(1), *resp[0].Id)
+	a.Len(s.calls, 2)
+
+	w = doRequest(h, `[{"jsonrpc":"2.0","method":"CountService.Do"},{"jsonrpc":"2.0","method":"CountService.Do"}]`)
+	a.Equal(http.StatusNoContent, w.Code)
+	a.Empty(w.Body.String())
+	a.Len(s.calls, 4)
+}
+
+type EchoService struct {
+	mu      sync.Mutex
+	running int
+	maxRun  int
+}
+
+func (es *EchoService) Call(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error) {
+	es.mu.Lock()
+	es.running++
+	if es.running > es.maxRun {
+		es.maxRun = es.running
+	}
+	es.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	es.mu.Lock()
+	es.running--
+	es.mu.Unlock()
+	return reqBody.GetMethod(), nil
+}
+
+func TestHandler_ServeHTTP_BatchOrder(t *testing.T) {
+	a := assert.New(t)
+	for _, c := range []struct {
+		concurrency int
+		maxRun      int
+	}{{BatchSequential, 1}, {3, 3}, {BatchUnlimited, 20}} {
+		h := NewHandler()
+		h.SetBatchConcurrency(c.concurrency)
+		s := new(EchoService)
+		h.Register(s)
+
+		reqs := make([]string, 20)
+		for i := range reqs {
+			reqs[i] = fmt.Sprintf(`{"jsonrpc":"2.0","method":"EchoService.M%d","id":%d}`, i, i)
+		}
+		w := doRequest(h, "["+strings.Join(reqs, ",")+"]")
+		a.Equal(http.StatusOK, w.Code)
+		var resp []*models.ResponseBody
+		a.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+		a.Len(resp, 20)
+		for i, rB := range resp {
+			a.Equal(float64(i), *rB.Id)
+			a.Equal(fmt.Sprintf(`"M%d"`, i), string(*rB.Result))
+		}
+		a.True(s.maxRun <= c.maxRun, s.maxRun)
+	}
+}
+
+type PanicService struct{}
+
+func (ps *PanicService) Call(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error) {
+	panic("boom")
+}
+
+func TestHandler_ServeHTTP_Panic(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	h.Register(new(PanicService))
+	var mu sync.Mutex
+	reported := make([]interface{}, 0)
+	h.SetPanicHandler(func(reqBody *models.RequestBody, r *http.Request, p interface{}, stack []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, p)
+		a.NotEmpty(stack)
+	})
+
+	w := doRequest(h, `{"jsonrpc":"2.0","method":"PanicService.Do","id":1}`)
+	a.Equal(http.StatusOK, w.Code)
+	var resp models.ResponseBody
+	a.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	a.Equal(models.ErrorCodeInternalError, resp.Error.Code)
+	a.Equal(float64(1), *resp.Id)
+
+	w = doRequest(h, `[{"jsonrpc":"2.0","method":"PanicService.Do","id":1},{"jsonrpc":"2.0","method":"PanicService.Do","id":2}]`)
+	a.Equal(http.StatusOK, w.Code)
+	var batchResp []*models.ResponseBody
+	a.NoError(json.Unmarshal(w.Body.Bytes(), &batchResp))
+	a.Len(batchResp, 2)
+	a.Equal(float64(2), *batchResp[1].Id)
+	a.Equal(models.ErrorCodeInternalError, batchResp[1].Error.Code)
+	a.Equal([]interface{}{"boom", "boom", "boom"}, reported)
+}
+
+type panicResult struct{}
+
+func (pr panicResult) MarshalJSON() ([]byte, error) {
+	panic("marshal boom")
+}
+
+type PanicResultService struct{}
+
+func (ps *PanicResultService) Call(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error) {
+	return panicResult{}, nil
+}
+
+type panicValidator struct{}
+
+func (pv panicValidator) Validate(service string, method string, params *json.RawMessage) error {
+	if method == "Validate" {
+		panic("validate boom")
+	}
+	return nil
+}
+
+func TestHandler_ServeHTTP_Panic_Batch(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	h.Register(new(PanicResultService))
+	h.Register(new(CountService))
+	h.SetValidator(panicValidator{})
+	var mu sync.Mutex
+	reported := make([]interface{}, 0)
+	h.SetPanicHandler(func(reqBody *models.RequestBody, r *http.Request, p interface{}, stack []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, p)
+	})
+
+	w := doRequest(h, `[
+		{"jsonrpc":"2.0","method":"PanicResultService.Do","id":1},
+		{"jsonrpc":"2.0","method":"CountService.Validate","id":2},
+		{"jsonrpc":"2.0","method":"CountService.Do","id":3}
+	]`)
+	a.Equal(http.StatusOK, w.Code)
+	var batchResp []*models.ResponseBody
+	a.NoError(json.Unmarshal(w.Body.Bytes(), &batchResp))
+	a.Len(batchResp, 3)
+	for i, rB := range batchResp[:2] {
+		a.Equal(float64(i+1), *rB.Id)
+		a.NotNil(rB.Error)
+		a.Equal(models.ErrorCodeInternalError, rB.Error.Code)
+	}
+	a.Nil(batchResp[2].Error)
+	a.ElementsMatch([]interface{}{"marshal boom", "validate boom"}, reported)
+}
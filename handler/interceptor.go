@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"github.com/andrskom/jrpc2hh/models"
+	"net/http"
+)
+
+// CallFunc has the same signature as Caller.Call and represents next step of interceptor chain
+type CallFunc func(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error)
+
+// Interceptor is called around Caller.Call, it can short-circuit by returning error without calling next
+// or wrap result of next
+type Interceptor func(reqBody *models.RequestBody, r *http.Request, next CallFunc) (interface{}, *models.Error)
+
+// Use adds interceptors called for every service and for rpc.discover, they are called in order of adding
+// and before interceptors of service
+func (h *Handler) Use(interceptors ...Interceptor) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.interceptors = append(h.interceptors, interceptors...)
+}
+
+// UseFor adds interceptors called only for service with the name
+func (h *Handler) UseFor(service string, interceptors ...Interceptor) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sInterceptors[service] = append(h.sInterceptors[service], interceptors...)
+}
+
+// chain wraps call of service by interceptors added before the call
+func (h *Handler) chain(sN string, c Caller) CallFunc {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	next := CallFunc(c.Call)
+	sInterceptors := h.sInterceptors[sN]
+	for i := len(sInterceptors) - 1; i >= 0; i-- {
+		next = wrap(sInterceptors[i], next)
+	}
+	return h.wrapGlobal(next)
+}
+
+// chainGlobal wraps call of method served by handler itself, e.g. rpc.discover, by global interceptors
+func (h *Handler) chainGlobal(call CallFunc) CallFunc {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.wrapGlobal(call)
+}
+
+// wrapGlobal must be called under lock
+func (h *Handler) wrapGlobal(next CallFunc) CallFunc {
+	for i := len(h.interceptors) - 1; i >= 0; i-- {
+		next = wrap(h.interceptors[i], next)
+	}
+	return next
+}
+
+func wrap(i Interceptor, next CallFunc) CallFunc {
+	return func(reqBody *models.RequestBody, r *http.Request) (interface{}, *models.Error) {
+		return i(reqBody, r, next)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/andrskom/jrpc2hh/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestHandler_Use(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	s := new(CountService)
+	h.Register(s)
+
+	order := make([]string, 0)
+	named := func(n string) Interceptor {
+		return func(reqBody *models.RequestBody, r *http.Request, next CallFunc) (interface{}, *models.Error) {
+			order = append(order, n)
+			return next(reqBody, r)
+		}
+	}
+	h.UseFor("CountService", named("service"))
+	h.Use(named("first"), named("second"))
+	h.UseFor("Unknown", named("unknown"))
+
+	w := doRequest(h, `{"jsonrpc":"2.0","method":"CountService.Do","id":1}`)
+	a.Equal(http.StatusOK, w.Code)
+	a.Equal([]string{"first", "second", "service"}, order)
+	a.Equal([]string{"Do"}, s.calls)
+}
+
+func TestHandler_Use_ShortCircuit(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	s := new(CountService)
+	h.Register(s)
+	h.Use(func(reqBody *models.RequestBody, r *http.Request, next CallFunc) (interface{}, *models.Error) {
+		return nil, models.NewError(-32001, "Unauthorized", nil)
+	})
+
+	w := doRequest(h, `{"jsonrpc":"2.0","method":"CountService.Do","id":1}`)
+	var resp models.ResponseBody
+	a.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	a.NotNil(resp.Error)
+	a.Equal(models.ErrorCode(-32001), resp.Error.Code)
+	a.Empty(s.calls)
+}
+
+func TestHandler_Use_Concurrent(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	s := new(CountService)
+	h.Register(s)
+	pass := func(reqBody *models.RequestBody, r *http.Request, next CallFunc) (interface{}, *models.Error) {
+		return next(reqBody, r)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			h.Use(pass)
+			h.UseFor("CountService", pass)
+		}()
+		go func() {
+			defer wg.Done()
+			doRequest(h, `{"jsonrpc":"2.0","method":"CountService.Do","id":1}`)
+		}()
+	}
+	wg.Wait()
+	a.Len(s.calls, 10)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"github.com/andrskom/jrpc2hh/models"
+	"strings"
+)
+
+// DefaultSeparator separates names of service and method, e.g. 'billing.invoices.create'
+const DefaultSeparator = "."
+
+// SetSeparator sets separator of names of service and method, e.g. '_' for methods like 'eth_getBalance',
+// it panics if separator is empty
+func (h *Handler) SetSeparator(sep string) {
+	if sep == "" {
+		panic("Separator of names of service and method must not be empty")
+	}
+	h.separator = sep
+}
+
+// RegisterRoot registers service handling methods without name of service, e.g. 'ping'
+func (h *Handler) RegisterRoot(c Caller) error {
+	return h.RegisterName("", c)
+}
+
+// route finds service with the longest name which is prefix of method followed by separator,
+// methods not matched by any service are routed to root service if it is registered
+func (h *Handler) route(jReq *models.RequestBody) (Caller, *models.Error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for end := len(jReq.Method); end > 0; {
+		i := strings.LastIndex(jReq.Method[:end], h.separator)
+		if i <= 0 {
+			break
+		}
+		if c, ok := h.sMap[jReq.Method[:i]]; ok && i+len(h.separator) < len(jReq.Method) {
+			jReq.SetRoute(jReq.Method[:i], jReq.Method[i+len(h.separator):])
+			return c, nil
+		}
+		end = i
+	}
+	if c, ok := h.sMap[""]; ok {
+		jReq.SetRoute("", jReq.Method)
+		return c, nil
+	}
+	return nil, models.NewError(
+		models.ErrorCodeMethodNotFound,
+		"Unknown service",
+		map[string]string{"methodName": jReq.Method})
+}
+
+// fullName returns name of method in requests
+func (h *Handler) fullName(sN string, mN string) string {
+	if sN == "" {
+		return mN
+	}
+	return sN + h.separator + mN
+}
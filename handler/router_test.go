@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestHandler_ServeHTTP_Namespaces(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	billing := new(CountService)
+	invoices := new(CountService)
+	root := new(CountService)
+	a.NoError(h.RegisterName("billing", billing))
+	a.NoError(h.RegisterName("billing.invoices", invoices))
+	a.NoError(h.RegisterRoot(root))
+
+	for _, method := range []string{"billing.invoices.create", "billing.refund", "billing.payments.list", "ping"} {
+		w := doRequest(h, `{"jsonrpc":"2.0","method":"`+method+`","id":1}`)
+		a.Equal(http.StatusOK, w.Code, method)
+	}
+	a.Equal([]string{"create"}, invoices.calls)
+	a.Equal([]string{"refund", "payments.list"}, billing.calls)
+	a.Equal([]string{"ping"}, root.calls)
+}
+
+func TestHandler_ServeHTTP_Separator(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	h.SetSeparator("_")
+	s := new(CountService)
+	a.NoError(h.RegisterName("eth", s))
+
+	w := doRequest(h, `{"jsonrpc":"2.0","method":"eth_getBalance","id":1}`)
+	a.Equal(http.StatusOK, w.Code)
+	a.Equal([]string{"getBalance"}, s.calls)
+
+	w = doRequest(h, `{"jsonrpc":"2.0","method":"net_version","id":1}`)
+	a.Equal(http.StatusOK, w.Code)
+	a.Contains(w.Body.String(), `"code":-32601`)
+}
+
+func TestHandler_SetSeparator_Empty(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	a.Panics(func() {
+		h.SetSeparator("")
+	})
+	a.Equal(DefaultSeparator, h.separator)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/andrskom/jrpc2hh/models"
+	"github.com/andrskom/jrpc2hh/openrpc"
+	"sync"
+)
+
+// SchemaValidator validates params with JSON Schemas from OpenRPC descriptions of services,
+// descriptions are embedded into services generated with -discover flag
+type SchemaValidator struct {
+	mu       sync.RWMutex
+	services map[string]*openrpc.Document
+}
+
+func NewSchemaValidator() *SchemaValidator {
+	return &SchemaValidator{services: make(map[string]*openrpc.Document)}
+}
+
+// AddService adds description of service, names of methods in description don't contain name of service
+func (v *SchemaValidator) AddService(service string, doc *openrpc.Document) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.services[service] = doc
+}
+
+// Validate returns invalid params error with list of openrpc.ValidationError in data,
+// params of methods without description aren't validated
+func (v *SchemaValidator) Validate(service string, method string, params *json.RawMessage) error {
+	v.mu.RLock()
+	doc, ok := v.services[service]
+	v.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	for _, m := range doc.Methods {
+		if m.Name != method && !models.Contains(m.Aliases, method) {
+			continue
+		}
+		if errs := m.ValidateParams(params, doc.Components); len(errs) != 0 {
+			return models.NewError(models.ErrorCodeInvalidParams, "Invalid params", errs)
+		}
+		return nil
+	}
+	return nil
+}
+
+// SchemaValidator returns validator with descriptions of all registered services implementing Describer
+func (h *Handler) SchemaValidator() *SchemaValidator {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	v := NewSchemaValidator()
+	for name, c := range h.sMap {
+		if d, ok := c.(Describer); ok {
+			v.AddService(name, d.Describe())
+		}
+	}
+	return v
+}
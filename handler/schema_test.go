@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/andrskom/jrpc2hh/models"
+	"github.com/andrskom/jrpc2hh/openrpc"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type ParamsService struct {
+	CountService
+}
+
+func (ps *ParamsService) Describe() *openrpc.Document {
+	doc := openrpc.NewDocument("", "")
+	doc.Methods = append(doc.Methods, &openrpc.Method{
+		Name:           "Do",
+		Aliases:        []string{"do"},
+		ParamStructure: "either",
+		Params: []*openrpc.ContentDescriptor{
+			{Name: "name", Required: true, Schema: &openrpc.Schema{Type: "string"}},
+		},
+		Result: &openrpc.ContentDescriptor{Name: "result", Schema: &openrpc.Schema{}},
+	})
+	return doc
+}
+
+func TestHandler_SchemaValidator(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	s := new(ParamsService)
+	h.Register(s)
+	h.SetValidator(h.SchemaValidator())
+
+	w := doRequest(h, `{"jsonrpc":"2.0","method":"ParamsService.Do","params":{"name":1},"id":1}`)
+	var resp struct {
+		Error struct {
+			Code models.ErrorCode          `json:"code"`
+			Data []openrpc.ValidationError `json:"data"`
+		} `json:"error"`
+	}
+	a.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	a.Equal(models.ErrorCodeInvalidParams, resp.Error.Code)
+	a.Equal([]openrpc.ValidationError{{Path: "/name", Message: "must be string"}}, resp.Error.Data)
+	a.Empty(s.calls)
+
+	w = doRequest(h, `{"jsonrpc":"2.0","method":"ParamsService.Do","params":["x"],"id":1}`)
+	a.Contains(w.Body.String(), `"result"`)
+	a.Equal([]string{"Do"}, s.calls)
+}
+
+func TestSchemaValidator_Validate_Alias(t *testing.T) {
+	a := assert.New(t)
+	v := NewSchemaValidator()
+	v.AddService("ParamsService", new(ParamsService).Describe())
+
+	for _, method := range []string{"Do", "do"} {
+		err := v.Validate("ParamsService", method, nil)
+		a.Error(err)
+		a.Equal(models.ErrorCodeInvalidParams, err.(*models.Error).Code)
+	}
+	a.NoError(v.Validate("ParamsService", "Unknown", nil))
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/andrskom/jrpc2hh/models"
+	"net/http"
+)
+
+// StatusPolicy returns HTTP status of response with body, jErr is error of response to single request
+// or nil for successful responses and batches, status is status used by handler before policies were introduced
+type StatusPolicy func(jErr *models.Error, status int) int
+
+// StatusAlwaysOK replies with 200 to every request having response, errors are described by error objects.
+// It is default policy following conventions of JSON-RPC over HTTP, so proxies don't retry requests with errors.
+func StatusAlwaysOK(jErr *models.Error, status int) int {
+	return http.StatusOK
+}
+
+// StatusLegacy keeps previous behaviour of handler: 400 for bad headers and malformed requests,
+// 404 for unknown services, 500 for invalid params and errors of methods
+func StatusLegacy(jErr *models.Error, status int) int {
+	return status
+}
+
+// SetStatusPolicy sets policy of HTTP statuses, replies to notifications are always 204 without body,
+// nil restores default StatusAlwaysOK
+func (h *Handler) SetStatusPolicy(p StatusPolicy) {
+	if p == nil {
+		p = StatusAlwaysOK
+	}
+	h.statusPolicy = p
+}
+
+// jsonResponse writes response with status chosen by policy
+func (h *Handler) jsonResponse(w http.ResponseWriter, data interface{}, jErr *models.Error, status int) {
+	models.JsonResponse(w, data, h.statusPolicy(jErr, status))
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"github.com/andrskom/jrpc2hh/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_StatusPolicy(t *testing.T) {
+	a := assert.New(t)
+	cases := []struct {
+		body   string
+		ok     int
+		legacy int
+	}{
+		{`{"jsonrpc":"2.0","method":"PanicService.Do","id":1}`, http.StatusOK, http.StatusInternalServerError},
+		{`{"jsonrpc":"2.0","method":"Unknown.Do","id":1}`, http.StatusOK, http.StatusNotFound},
+		{`{"jsonrpc":"1.0","method":"PanicService.Do","id":1}`, http.StatusOK, http.StatusBadRequest},
+		{`{"jsonrpc":"2.0","method":"CountService.Do","id":1}`, http.StatusOK, http.StatusOK},
+		{`[{"jsonrpc":"2.0","method":"Unknown.Do","id":1}]`, http.StatusOK, http.StatusOK},
+		{`{`, http.StatusOK, http.StatusBadRequest},
+		{`{"jsonrpc":"2.0","method":"CountService.Do"}`, http.StatusNoContent, http.StatusNoContent},
+	}
+	for _, c := range cases {
+		for _, p := range []struct {
+			policy StatusPolicy
+			status int
+		}{{nil, c.ok}, {StatusLegacy, c.legacy}} {
+			h := NewHandler()
+			h.Register(new(PanicService))
+			h.Register(new(CountService))
+			// nil restores default policy
+			h.SetStatusPolicy(p.policy)
+			a.Equal(p.status, doRequest(h, c.body).Code, c.body)
+		}
+	}
+}
+
+func TestHandler_StatusPolicy_Custom(t *testing.T) {
+	a := assert.New(t)
+	h := NewHandler()
+	h.SetStatusPolicy(func(jErr *models.Error, status int) int {
+		if jErr != nil && jErr.Code == models.ErrorCodeMethodNotFound {
+			return http.StatusNotImplemented
+		}
+		return http.StatusOK
+	})
+	a.Equal(http.StatusNotImplemented, doRequest(h, `{"jsonrpc":"2.0","method":"Unknown.Do","id":1}`).Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	a.Equal(http.StatusOK, w.Code)
+}
@@ -2,38 +2,112 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"github.com/andrskom/jrpc2hh/gen/diag"
+	"github.com/andrskom/jrpc2hh/gen/diff"
 	"github.com/andrskom/jrpc2hh/gen/imports"
 	"github.com/andrskom/jrpc2hh/gen/method"
+	"github.com/andrskom/jrpc2hh/gen/schema"
 	"github.com/andrskom/jrpc2hh/gen/service"
+	"github.com/andrskom/jrpc2hh/gen/source"
 	"github.com/andrskom/jrpc2hh/gen/templates"
-	"go/ast"
-	"go/parser"
+	"github.com/andrskom/jrpc2hh/openrpc"
 	"go/token"
+	"golang.org/x/tools/go/packages"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
-	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 )
 
-var hDir string
-var pack string
+var sPattern string
+var genClient bool
+var openrpcFile string
+var openrpcVersion string
+var genDiscover bool
+var check bool
+var separator string
+
+// generatedFiles contains content of generated files by path
+type generatedFiles map[string][]byte
+
+// servicePackage is package containing annotated services
+type servicePackage struct {
+	name string
+	dir  string
+	iMap *imports.ImportMap
+	sl   service.ServiceList
+	ml   method.MethodList
+}
 
 func main() {
-	flag.StringVar(&hDir, "s", "./testservice", "Service dir")
+	flag.StringVar(&sPattern, "s", "./testservice", "Service dir or pattern of packages, e.g. ./services/...")
+	flag.BoolVar(&genClient, "client", false, "Generate go client for every service")
+	flag.StringVar(&openrpcFile, "openrpc", "", "File for OpenRPC document of services, isn't generated if empty")
+	flag.StringVar(&openrpcVersion, "openrpcVersion", "1.0.0", "Version of api in OpenRPC document")
+	flag.BoolVar(&genDiscover, "discover", false, "Generate OpenRPC description of services for rpc.discover")
+	flag.BoolVar(&check, "check", false, "Check that generated files are up to date without writing them")
+	flag.StringVar(&separator, "separator", ".", "Separator of service and method names used by handler")
 	flag.Parse()
-
-	err := cleanAutoGeneratedFiles(hDir)
-	logFatal("Error in autogenrated file deliting", err)
+	if separator == "" {
+		log.Fatal("Separator must not be empty")
+	}
 
 	fs := token.NewFileSet()
-	packages, err := parser.ParseDir(fs, hDir, nil, parser.ParseComments)
-	logFatal("Parsing dir error", err)
+	pkgs, err := loadPackages(fs, sPattern)
+	logFatal("Loading packages error", err)
 
+	d := diag.NewDiagnostics(fs)
+	sps, dirs := parsePackages(pkgs, d)
+	if d.HasErrors() {
+		d.Report(os.Stderr)
+		os.Exit(1)
+	}
+
+	files := make(generatedFiles)
+	for _, sp := range sps {
+		var sb *schema.Builder
+		if genDiscover {
+			sb = schema.NewBuilder()
+			sb.SetSeparator(separator)
+		}
+		generate(sp, sb, files)
+		if genClient {
+			generateClients(sp, files)
+		}
+	}
+	if openrpcFile != "" {
+		generateOpenRPC(sps, files)
+	}
+
+	if check {
+		stale, err := checkGeneratedFiles(dirs, files, os.Stdout)
+		logFatal("Can't check generated files", err)
+		if stale {
+			os.Exit(1)
+		}
+		return
+	}
+	for _, dir := range dirs {
+		err := cleanAutoGeneratedFiles(dir)
+		logFatal("Error in autogenrated file deliting", err)
+	}
+	for path, data := range files {
+		err := ioutil.WriteFile(path, data, 0644)
+		logFatal("Can't write generated file", err)
+	}
+}
+
+// parsePackages returns packages containing annotated services and directories of all packages
+func parsePackages(pkgs []*packages.Package, d *diag.Diagnostics) ([]*servicePackage, []string) {
 	regExpService, err := regexp.Compile("//[ ]*jrpc2hh:service")
 	logFatal("Compiling regexp for service error", err)
 
@@ -43,78 +117,203 @@ func main() {
 	regExpMethodWithContext, err := regexp.Compile("//[ ]*jrpc2hh:method:withContext")
 	logFatal("Compiling regep for method with context error", err)
 
-	if len(packages) != 1 {
-		log.Fatal("Expected that only one package will be parse")
+	dirs := make([]string, 0, len(pkgs))
+	sps := make([]*servicePackage, 0)
+	for _, pkg := range pkgs {
+		dir := packageDir(pkg)
+		dirs = append(dirs, dir)
+		iMap, sl, ml := parse(regExpService, regExpMethod, regExpMethodWithContext, pkg, d)
+		if len(ml) == 0 {
+			continue
+		}
+		iMap.GenerateAlias()
+		sps = append(sps, &servicePackage{pkg.Name, dir, iMap, sl, ml})
 	}
-	for p, _ := range packages {
-		pack = p
+	return sps, dirs
+}
+
+// addGoFile formats generated code and removes unused imports, invalid code is fatal error
+func addGoFile(files generatedFiles, path string, src []byte) {
+	formatted, err := source.Format(path, src)
+	logFatal(fmt.Sprintf("Generated code of %s is invalid", path), err)
+	files[path] = formatted
+}
+
+// checkGeneratedFiles writes diff of existing and generated files and returns true if they differ
+func checkGeneratedFiles(dirs []string, files generatedFiles, w io.Writer) (bool, error) {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	for _, dir := range dirs {
+		existing, err := autoGeneratedFiles(dir)
+		if err != nil {
+			return false, err
+		}
+		for _, path := range existing {
+			if _, ok := files[path]; !ok {
+				paths = append(paths, path)
+			}
+		}
 	}
+	sort.Strings(paths)
 
-	iMap, sl, ml := parse(regExpService, regExpMethod, regExpMethodWithContext, packages)
-	iMap.GenerateAlias()
-	generate(iMap, sl, ml)
+	stale := false
+	for _, path := range paths {
+		old, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		if d := diff.Unified(path, path, old, files[path]); d != "" {
+			stale = true
+			fmt.Fprint(w, d)
+		}
+	}
+	return stale, nil
 }
 
-func generate(iMap *imports.ImportMap, sl service.ServiceList, ml method.MethodList) {
+// generateOpenRPC generates one document for services of all packages
+func generateOpenRPC(sps []*servicePackage, files generatedFiles) {
+	names := make([]string, 0, len(sps))
+	for _, sp := range sps {
+		names = append(names, sp.name)
+	}
+	doc := openrpc.NewDocument(strings.Join(names, ", "), openrpcVersion)
+	// one builder qualifies names of components of all packages
+	sb := schema.NewBuilder()
+	sb.SetSeparator(separator)
+	for _, sp := range sps {
+		doc.Methods = append(doc.Methods, sb.Methods(sp.sl, sp.ml)...)
+	}
+	if components := sb.Components(); len(components) != 0 {
+		doc.Components = &openrpc.Components{Schemas: components}
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	logFatal("Can't marshal OpenRPC document", err)
+	files[openrpcFile] = data
+}
+
+func generate(sp *servicePackage, sb *schema.Builder, files generatedFiles) {
+	iMap := sp.iMap
 	sTmpl, err := template.New("serviceTemplate").Parse(templates.Service)
 	logFatal("Can't parse service template", err)
 
 	mTmpl, err := template.New("methodTemplate").Parse(templates.Method)
 	logFatal("Can't parse method template", err)
 
-	for sn, sm := range ml {
+	for sn, sm := range sp.ml {
 		usedImports := make(map[string]string)
 		usedImports["encoding/json"] = "json"
 		usedImports["github.com/andrskom/jrpc2hh/models"] = "jModels"
 		methods := make([]string, 0)
 		for _, m := range sm {
-			args := generateArgsBlock(m.Args, &usedImports, iMap)
+			args := generateArgsBlock(m.Args, m.Params, &usedImports, iMap)
 			if m.ArgsWithContext {
 				args = args + `
 		args.WithContext(r.Context())`
 			}
-			res := generateResBlock(m.Result, &usedImports, iMap)
+			var res string
+			if !m.ReturnResult {
+				res = generateResBlock(m.Result, &usedImports, iMap)
+			}
 
 			buf := bytes.NewBuffer(make([]byte, 0))
+			names := make([]string, 0, len(m.WireNames()))
+			for _, n := range m.WireNames() {
+				names = append(names, strconv.Quote(n))
+			}
 			mTmpl.Execute(buf, struct {
-				Method      string
-				ArgsBlock   string
-				ResultBlock string
-			}{m.Name, args, res})
+				Names        string
+				Method       string
+				ArgsBlock    string
+				ResultBlock  string
+				WithContext  bool
+				ReturnResult bool
+				ArgsPointer  bool
+			}{strings.Join(names, ", "), m.Name, args, res, m.WithContext, m.ReturnResult, m.Args.Prefix == "*"})
 			methods = append(methods, buf.String())
 		}
 
-		file, err := os.OpenFile(fmt.Sprintf("%s/jrpc2hh_%s.go", hDir, strings.ToLower(sn)),
-			os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
-			0755)
-		if err != nil {
-			log.Fatalf(fmt.Sprintf("Can't open file for writing generated data, %s", err.Error()))
+		var openRPC string
+		if sb != nil {
+			doc, err := json.Marshal(sb.Service(sm))
+			logFatal("Can't marshal OpenRPC description of service", err)
+			openRPC = strconv.Quote(string(doc))
+			usedImports["github.com/andrskom/jrpc2hh/openrpc"] = "jOpenRPC"
+		}
+
+		buf := bytes.NewBuffer(make([]byte, 0))
+		sTmpl.Execute(buf, struct {
+			Package     string
+			Imports     map[string]string
+			Service     string
+			ServiceName string
+			Methods     []string
+			OpenRPC     string
+		}{sp.name, usedImports, sn, sp.sl.WireName(sn), methods, openRPC})
+		addGoFile(files, filepath.Join(sp.dir, fmt.Sprintf("jrpc2hh_%s.go", strings.ToLower(sn))), buf.Bytes())
+	}
+}
+
+func generateClients(sp *servicePackage, files generatedFiles) {
+	iMap := sp.iMap
+	cTmpl, err := template.New("clientTemplate").Parse(templates.Client)
+	logFatal("Can't parse client template", err)
+
+	cmTmpl, err := template.New("clientMethodTemplate").Parse(templates.ClientMethod)
+	logFatal("Can't parse client method template", err)
+
+	for sn, sm := range sp.ml {
+		usedImports := make(map[string]string)
+		usedImports["context"] = iMap.GetFormattedAlias("context")
+		usedImports["github.com/andrskom/jrpc2hh/client"] = "jClient"
+		methods := make([]string, 0)
+		for _, m := range sm {
+			var argsType string
+			if m.Args.Pack+m.Args.Name != "github.com/andrskom/jrpc2hh/modelsNilArgs" {
+				argsType = generateTypeName(m.Args, &usedImports, iMap)
+			}
+			buf := bytes.NewBuffer(make([]byte, 0))
+			cmTmpl.Execute(buf, struct {
+				Service     string
+				ServiceName string
+				Separator   string
+				Method      string
+				WireName    string
+				ArgsType    string
+				ResultType  string
+			}{sn, sp.sl.WireName(sn), separator, m.Name, m.WireName, argsType, generateTypeName(m.Result, &usedImports, iMap)})
+			methods = append(methods, buf.String())
 		}
-		sTmpl.Execute(file, struct {
+
+		buf := bytes.NewBuffer(make([]byte, 0))
+		cTmpl.Execute(buf, struct {
 			Package string
 			Imports map[string]string
 			Service string
 			Methods []string
-		}{pack, usedImports, sn, methods})
+		}{sp.name, usedImports, sn, methods})
+		addGoFile(files, filepath.Join(sp.dir, fmt.Sprintf("jrpc2hh_%s_client.go", strings.ToLower(sn))), buf.Bytes())
 	}
 }
 
+func generateTypeName(s *method.Struct, ui *map[string]string, iMap *imports.ImportMap) string {
+	if s.Pack == "" {
+		return s.Prefix + s.Name
+	}
+	(*ui)[s.Pack] = iMap.GetFormattedAlias(s.Pack)
+	return s.Prefix + (*ui)[s.Pack] + "." + s.Name
+}
+
 func generateResBlock(res *method.Struct, ui *map[string]string, iMap *imports.ImportMap) string {
 	if res.Pack+res.Name == "github.com/andrskom/jrpc2hh/modelsNilResult" {
 		return `var res jModels.NilResult`
 	} else {
-		var resType string
-		if res.Pack != "" {
-			(*ui)[res.Pack] = iMap.GetFormattedAlias(res.Pack)
-			resType = res.Prefix + res.Pack + "." + res.Name
-		} else {
-			resType = res.Prefix + res.Name
-		}
-		return "var res " + resType
+		return "var res " + generateTypeName(res, ui, iMap)
 	}
 }
 
-func generateArgsBlock(args *method.Struct, ui *map[string]string, iMap *imports.ImportMap) string {
+func generateArgsBlock(args *method.Struct, p method.Params, ui *map[string]string, iMap *imports.ImportMap) string {
 	if args.Pack+args.Name == "github.com/andrskom/jrpc2hh/modelsNilArgs" {
 		(*ui)[args.Pack] = iMap.GetFormattedAlias(args.Pack)
 		return templates.ArgsEmpty
@@ -126,167 +325,26 @@ func generateArgsBlock(args *method.Struct, ui *map[string]string, iMap *imports
 		} else {
 			argsType = args.Name
 		}
-		return fmt.Sprintf(templates.Args, argsType)
+		return fmt.Sprintf(templates.Args, argsType, generateParamsOptions(p))
 	}
 }
 
-func parse(regExpService *regexp.Regexp, regExpMethod *regexp.Regexp, regExpMethodWithContext *regexp.Regexp, packages map[string]*ast.Package) (*imports.ImportMap, service.ServiceList, method.MethodList) {
-	iMap := imports.NewImportMap()
-	sl := make(service.ServiceList)
-	ml := make(method.MethodList)
-
-	for _, p := range packages {
-		for _, f := range p.Files {
-			// collect imports
-			localIMap := make(map[string]string)
-			for _, im := range f.Imports {
-				pV := strings.Trim(im.Path.Value, "\"")
-				iMap.Register(pV)
-				if im.Name != nil {
-					localIMap[im.Name.String()] = pV
-				} else {
-					localIMap[pV] = pV
-				}
-			}
-
-			// collect structs
-			for _, d := range f.Decls {
-				//collect services
-				if reflect.TypeOf(d).Elem().Name() == "GenDecl" {
-					gd, ok := (d).(*ast.GenDecl)
-					if !ok {
-						log.Fatal("Bad assertation type GenDecl")
-					}
-					if gd.Tok.String() == "type" && docHasMatch(regExpService, gd.Doc) {
-						if len(gd.Specs) != 1 {
-							log.Fatal("Bad Specs for type")
-						}
-						spec, ok := gd.Specs[0].(*ast.TypeSpec)
-						if !ok {
-							log.Fatal("Bad assertation TypeSpec")
-						}
-						if spec.Name == nil {
-							log.Fatal("Bad Ident for types spec")
-						}
-						sl.Add(spec.Name.Name)
-					}
-					// collect methods
-				} else if reflect.TypeOf(d).Elem().Name() == "FuncDecl" {
-					fd, ok := (d).(*ast.FuncDecl)
-					if !ok {
-						log.Fatal("Bad assertation func FunDecl")
-					}
-					if docHasMatch(regExpMethod, fd.Doc) {
-						mN := fd.Name.String()
-						if fd.Recv == nil {
-							log.Fatal("Recv of service method can't be nil")
-						}
-						if len(fd.Recv.List) != 1 {
-							log.Fatal("List of recv service method can't be nil")
-						}
-						mT, ok := (fd.Recv.List[0].Type).(*ast.StarExpr)
-						if !ok {
-							log.Fatal("Type associated with method must be pointer")
-						}
-						i, ok := (mT.X).(*ast.Ident)
-						if !ok {
-							log.Fatal("Bad Ident for method spec")
-						}
-						assType := i.Name
-
-						if len(fd.Type.Params.List) != 2 {
-							log.Fatal("Count of params must be equal 2")
-						}
-						argsAst := fd.Type.Params.List[0]
-						resAst := fd.Type.Params.List[1]
-
-						var args *method.Struct
-						var res *method.Struct
-
-						switch reflect.TypeOf(argsAst.Type).String() {
-						case "*ast.SelectorExpr":
-							t := (argsAst.Type).(*ast.SelectorExpr)
-							x, ok := (t.X).(*ast.Ident)
-							if !ok {
-								log.Fatal("Bad assertation type")
-							}
-							if _, ok := localIMap[x.Name]; !ok {
-								log.Fatal("Problem with inport and alias")
-							}
-							args = method.NewStruct(localIMap[x.Name], t.Sel.Name)
-						case "*ast.Ident":
-							t := (argsAst.Type).(*ast.Ident)
-							args = method.NewStruct("", t.Name)
-						default:
-							log.Fatal("Unknown type of args")
-						}
-
-						if reflect.TypeOf(resAst.Type).String() != "*ast.StarExpr" {
-							log.Fatal("Result must be pointer")
-						}
-
-						sE, _ := (resAst.Type).(*ast.StarExpr)
-
-						switch reflect.TypeOf(sE.X).String() {
-						case "*ast.StarExpr":
-							t := (sE.X).(*ast.StarExpr)
-							switch reflect.TypeOf(t.X).String() {
-							case "*ast.Ident":
-								t := (t.X).(*ast.Ident)
-								res = method.NewStruct("", t.Name)
-								res.SetPrefix("*")
-							case "*ast.SelectorExpr":
-								t := (t.X).(*ast.SelectorExpr)
-								x, ok := (t.X).(*ast.Ident)
-								if !ok {
-									log.Fatal("Bad assertation type")
-								}
-								if _, ok := localIMap[x.Name]; !ok {
-									log.Fatal("Problem with inport and alias")
-								}
-								res = method.NewStruct(localIMap[x.Name], t.Sel.Name)
-								res.SetPrefix("*")
-							default:
-								log.Fatal("Unknown type of res")
-							}
-						case "*ast.SelectorExpr":
-							t := (sE.X).(*ast.SelectorExpr)
-							x, ok := (t.X).(*ast.Ident)
-							if !ok {
-								log.Fatal("Bad assertation type")
-							}
-							if _, ok := localIMap[x.Name]; !ok {
-								log.Fatal("Problem with inport and alias")
-							}
-							res = method.NewStruct(localIMap[x.Name], t.Sel.Name)
-						case "*ast.Ident":
-							t := (sE.X).(*ast.Ident)
-							res = method.NewStruct("", t.Name)
-						default:
-							log.Fatal("Unknown type of res")
-						}
-						withContext := docHasMatch(regExpMethodWithContext, fd.Doc)
-						ml.Add(assType, method.NewMethod(mN, args, res, withContext))
-					}
-				}
-			}
-		}
+func generateParamsOptions(p method.Params) string {
+	fields := make([]string, 0)
+	if p.Required {
+		fields = append(fields, "Required: true")
 	}
-
-	return iMap, sl, ml
-}
-
-func docHasMatch(regexp *regexp.Regexp, doc *ast.CommentGroup) bool {
-	res := false
-	if doc != nil {
-		for _, cm := range doc.List {
-			if regexp.Match([]byte(cm.Text)) {
-				res = true
-			}
+	if p.DisallowUnknownFields {
+		fields = append(fields, "DisallowUnknownFields: true")
+	}
+	if len(p.RequiredFields) != 0 {
+		names := make([]string, 0, len(p.RequiredFields))
+		for _, n := range p.RequiredFields {
+			names = append(names, strconv.Quote(n))
 		}
+		fields = append(fields, fmt.Sprintf("RequiredFields: []string{%s}", strings.Join(names, ", ")))
 	}
-
-	return res
+	return "jModels.ParamsOptions{" + strings.Join(fields, ", ") + "}"
 }
 
 func logFatal(comment string, err error) {
@@ -296,15 +354,17 @@ func logFatal(comment string, err error) {
 }
 
 func cleanAutoGeneratedFiles(sDir string) error {
-	regExpFile, err := regexp.Compile(`.*/jrpc2hh_.+\.go`)
+	paths, err := autoGeneratedFiles(sDir)
 	if err != nil {
 		return err
 	}
-	dFun := func(path string, f os.FileInfo, err error) error {
-		if regExpFile.Match([]byte(path)) {
-			os.Remove(path)
-		}
-		return nil
+	for _, path := range paths {
+		os.Remove(path)
 	}
-	return filepath.Walk(sDir, dFun)
+	return nil
+}
+
+// autoGeneratedFiles returns generated files of package in dir, subdirectories contain another packages
+func autoGeneratedFiles(sDir string) ([]string, error) {
+	return filepath.Glob(filepath.Join(sDir, "jrpc2hh_*.go"))
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/andrskom/jrpc2hh/gen/diag"
+	"github.com/andrskom/jrpc2hh/gen/schema"
+	"github.com/andrskom/jrpc2hh/handler"
+	"github.com/andrskom/jrpc2hh/models"
+	"github.com/andrskom/jrpc2hh/openrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateOpenRPC_Packages(t *testing.T) {
+	a := assert.New(t)
+	openrpcFile, separator = "openrpc.json", "."
+	defer func() {
+		openrpcFile, separator = "", ""
+	}()
+
+	fs := token.NewFileSet()
+	pkgs, err := loadPackages(fs, "./testdata/multi/...")
+	a.NoError(err)
+	d := diag.NewDiagnostics(fs)
+	sps, dirs := parsePackages(pkgs, d)
+	a.False(d.HasErrors())
+	a.Len(dirs, 3)
+	a.Len(sps, 2)
+
+	files := make(generatedFiles)
+	generateOpenRPC(sps, files)
+	var doc openrpc.Document
+	a.NoError(json.Unmarshal(files["openrpc.json"], &doc))
+
+	names := make([]string, 0)
+	results := make([]string, 0)
+	for _, m := range doc.Methods {
+		names = append(names, m.Name)
+		results = append(results, m.Result.Schema.Ref)
+	}
+	a.Equal([]string{"users.Get", "orders.Get"}, names)
+	// types of different packages with the same name don't overwrite each other
+	a.NotEqual(results[0], results[1])
+	a.Len(doc.Components.Schemas, 2)
+	a.Contains(doc.Components.Schemas, "first.Args")
+	a.Contains(doc.Components.Schemas, "github.com.andrskom.jrpc2hh.testdata.multi.second.first.Args")
+	a.Contains(doc.Components.Schemas["first.Args"].Properties, "id")
+	a.Equal("number", doc.Methods[1].Params[0].Name)
+	a.True(doc.Methods[1].Params[0].Required)
+}
+
+func TestSchemaValidator_TestService(t *testing.T) {
+	a := assert.New(t)
+	fs := token.NewFileSet()
+	pkgs, err := loadPackages(fs, "./testservice")
+	a.NoError(err)
+	d := diag.NewDiagnostics(fs)
+	sps, _ := parsePackages(pkgs, d)
+	a.False(d.HasErrors())
+	a.Len(sps, 1)
+
+	v := handlers.NewSchemaValidator()
+	for sn, sm := range sps[0].ml {
+		doc := schema.NewBuilder().Service(sm)
+		v.AddService(sps[0].sl.WireName(sn), doc)
+		for _, m := range doc.Methods {
+			if sn == "Test2" && m.Name == "nil_args" {
+				a.Equal([]string{"NilArgs"}, m.Aliases)
+			}
+		}
+	}
+
+	// args declared in other package are described by fields with required option of annotation
+	params := json.RawMessage(`{"optional_param":1}`)
+	err = v.Validate("test2", "NilResult", &params)
+	a.Error(err)
+	a.Equal(models.ErrorCodeInvalidParams, err.(*models.Error).Code)
+	a.Equal([]openrpc.ValidationError{{Path: "/required_param", Message: "is required"}}, err.(*models.Error).Data)
+	params = json.RawMessage(`{"required_param":"x","optional_param":"y"}`)
+	a.Error(v.Validate("test2", "NilResult", &params))
+	params = json.RawMessage(`{"required_param":"x","optional_param":1}`)
+	a.NoError(v.Validate("test2", "NilResult", &params))
+
+}
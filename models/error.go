@@ -1,6 +1,9 @@
 package models
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type ErrorCode int
 
@@ -10,6 +13,9 @@ const (
 	ErrorCodeMethodNotFound ErrorCode = -32601
 	ErrorCodeInvalidParams  ErrorCode = -32602
 	ErrorCodeInternalError  ErrorCode = -32603
+	// Range -32099..-32000 is reserved for implementation-defined server errors
+	ErrorCodeServerErrorMin ErrorCode = -32099
+	ErrorCodeServerErrorMax ErrorCode = -32000
 )
 
 type Error struct {
@@ -25,3 +31,30 @@ func NewError(code ErrorCode, mes string, data interface{}) *Error {
 func (e *Error) Error() string {
 	return fmt.Sprintf("Code: %d, Message: '%s', Data, %+v", e.Code, e.Message, e.Data)
 }
+
+// ApplicationError is implemented by errors of service methods which must be sent to client as is
+type ApplicationError interface {
+	error
+	JsonRpcCode() ErrorCode
+	JsonRpcMessage() string
+	JsonRpcData() interface{}
+}
+
+// NewErrorFromError converts error returned by service method to json rpc error.
+// *Error and ApplicationError found in chain of err are returned with their code,
+// any other error is internal error. Nil *Error returned as error is internal error too,
+// it must not be sent as success.
+func NewErrorFromError(err error) *Error {
+	var jErr *Error
+	if errors.As(err, &jErr) {
+		if jErr == nil {
+			return NewError(ErrorCodeInternalError, "Internal error", "nil *models.Error returned as error")
+		}
+		return jErr
+	}
+	var aErr ApplicationError
+	if errors.As(err, &aErr) {
+		return NewError(aErr.JsonRpcCode(), aErr.JsonRpcMessage(), aErr.JsonRpcData())
+	}
+	return NewError(ErrorCodeInternalError, "Internal error", err.Error())
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type notFoundError struct {
+	id int
+}
+
+func (e notFoundError) Error() string {
+	return fmt.Sprintf("entity %d not found", e.id)
+}
+
+func (e notFoundError) JsonRpcCode() ErrorCode {
+	return -32004
+}
+
+func (e notFoundError) JsonRpcMessage() string {
+	return "Not found"
+}
+
+func (e notFoundError) JsonRpcData() interface{} {
+	return map[string]int{"id": e.id}
+}
+
+func TestNewErrorFromError(t *testing.T) {
+	a := assert.New(t)
+
+	jErr := NewError(-32010, "Insufficient funds", nil)
+	a.Equal(jErr, NewErrorFromError(fmt.Errorf("withdraw: %w", jErr)))
+
+	a.Equal(
+		NewError(-32004, "Not found", map[string]int{"id": 1}),
+		NewErrorFromError(fmt.Errorf("get: %w", notFoundError{1})))
+
+	a.Equal(
+		NewError(ErrorCodeInternalError, "Internal error", "db is down"),
+		NewErrorFromError(errors.New("db is down")))
+
+	var nilErr *Error
+	jErr = NewErrorFromError(nilErr)
+	a.NotNil(jErr)
+	a.Equal(ErrorCodeInternalError, jErr.Code)
+}
@@ -0,0 +1,305 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// TagName is key of struct tag with options of params, e.g. `jrpc2hh:"required"`
+const TagName = "jrpc2hh"
+
+// ParamsOptions configures strictness of params decoding
+type ParamsOptions struct {
+	// Required rejects requests without params
+	Required bool
+	// DisallowUnknownFields rejects params with names not matching fields of args and their nested structures
+	DisallowUnknownFields bool
+	// RequiredFields are json names of fields which must be passed in addition to fields tagged as required
+	RequiredFields []string
+}
+
+// ParamsErrorData is data of error about params which can't be accepted by method
+type ParamsErrorData struct {
+	Unknown []string `json:"unknown,omitempty"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+// DecodeParams unmarshals params to args and checks them with options, problems are returned as invalid params error
+func DecodeParams(params *json.RawMessage, args interface{}, opts ParamsOptions) *Error {
+	if params == nil {
+		if opts.Required {
+			return NewError(ErrorCodeInvalidParams, "Params are required", nil)
+		}
+		return paramsError(nil, missingParams(reflect.TypeOf(args), opts, nil, 0))
+	}
+	if err := UnmarshalParams(*params, args); err != nil {
+		return NewError(ErrorCodeInvalidParams, "Can't unmarshal params to args structure", err.Error())
+	}
+
+	var byName map[string]json.RawMessage
+	if p := bytes.TrimLeft(*params, " \t\r\n"); len(p) != 0 && p[0] == '{' {
+		if err := json.Unmarshal(*params, &byName); err != nil {
+			return NewError(ErrorCodeInvalidParams, "Can't unmarshal params to args structure", err.Error())
+		}
+	}
+	var values []json.RawMessage
+	if byName == nil {
+		json.Unmarshal(*params, &values)
+	}
+	var unknown []string
+	if opts.DisallowUnknownFields && byName != nil {
+		unknown = unknownParams(reflect.TypeOf(args), byName, "")
+	} else if opts.DisallowUnknownFields {
+		// objects passed by position are checked like fields of the same position
+		for i, f := range paramFields(reflect.TypeOf(args)) {
+			if i < len(values) {
+				unknown = append(unknown, unknownNested(f.typ, values[i], f.name)...)
+			}
+		}
+		sort.Strings(unknown)
+	}
+	return paramsError(unknown, missingParams(reflect.TypeOf(args), opts, byName, len(values)))
+}
+
+func paramsError(unknown []string, missing []string) *Error {
+	if len(unknown) == 0 && len(missing) == 0 {
+		return nil
+	}
+	return NewError(ErrorCodeInvalidParams, "Invalid params", ParamsErrorData{unknown, missing})
+}
+
+// unknownParams returns sorted paths of params which don't match any field,
+// objects passed as values of fields are checked with fields of nested structures
+func unknownParams(t reflect.Type, byName map[string]json.RawMessage, path string) []string {
+	fields := paramFields(t)
+	unknown := make([]string, 0)
+	for n, v := range byName {
+		f, ok := findField(fields, n)
+		if !ok {
+			unknown = append(unknown, path+n)
+			continue
+		}
+		unknown = append(unknown, unknownNested(f.typ, v, path+n)...)
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// findField matches name of param with name of field exactly or case-insensitively like encoding/json does
+func findField(fields []paramField, name string) (paramField, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return paramField{}, false
+}
+
+// unknownNested returns paths of unknown params of structures in value, types with custom unmarshalling aren't checked
+func unknownNested(t reflect.Type, raw json.RawMessage, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		return nil
+	}
+	var unknown []string
+	switch t.Kind() {
+	case reflect.Struct:
+		var byName map[string]json.RawMessage
+		if json.Unmarshal(raw, &byName) == nil {
+			unknown = unknownParams(t, byName, path+".")
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		json.Unmarshal(raw, &items)
+		for i, item := range items {
+			unknown = append(unknown, unknownNested(t.Elem(), item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		var values map[string]json.RawMessage
+		json.Unmarshal(raw, &values)
+		for k, v := range values {
+			unknown = append(unknown, unknownNested(t.Elem(), v, path+"."+k)...)
+		}
+	}
+	return unknown
+}
+
+// missingParams returns names of required fields which aren't passed by name or by position
+func missingParams(t reflect.Type, opts ParamsOptions, byName map[string]json.RawMessage, count int) []string {
+	missing := make([]string, 0)
+	for i, f := range paramFields(t) {
+		if !f.required && !Contains(opts.RequiredFields, f.name) {
+			continue
+		}
+		if byName == nil && i < count {
+			continue
+		}
+		if !hasParam(byName, f.name) {
+			missing = append(missing, f.name)
+		}
+	}
+	return missing
+}
+
+// hasParam matches name case-insensitively like encoding/json does
+func hasParam(byName map[string]json.RawMessage, name string) bool {
+	_, ok := paramValue(byName, name)
+	return ok
+}
+
+// paramValue returns value of param matching name exactly or case-insensitively like encoding/json does
+func paramValue(byName map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if v, ok := byName[name]; ok {
+		return v, true
+	}
+	for n, v := range byName {
+		if strings.EqualFold(n, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+type paramField struct {
+	name     string
+	required bool
+	typ      reflect.Type
+}
+
+// paramFields returns fields of args structure in the same order as positionalFields
+func paramFields(t reflect.Type) []paramField {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := make([]paramField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if f.Type.Kind() != reflect.Ptr || f.PkgPath == "" {
+					fields = append(fields, paramFields(ft)...)
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, paramField{name, hasRule(f.Tag.Get(TagName), RuleRequired), f.Type})
+	}
+	return fields
+}
+
+// hasRule returns true if tag contains rule, bad rules are reported by ValidateArgs
+func hasRule(tag string, name string) bool {
+	rules, _ := cachedRules(tag)
+	for _, r := range rules {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns true if list contains s
+func Contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalParams unmarshals params to args, params passed by position are mapped
+// to exported fields of args structure in order of declaration, fields of embedded structures are promoted
+func UnmarshalParams(params json.RawMessage, args interface{}) error {
+	if p := bytes.TrimLeft(params, " \t\r\n"); len(p) == 0 || p[0] != '[' {
+		return json.Unmarshal(params, args)
+	}
+	if _, ok := args.(json.Unmarshaler); ok {
+		return json.Unmarshal(params, args)
+	}
+	v := reflect.ValueOf(args)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return json.Unmarshal(params, args)
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(params, &values); err != nil {
+		return err
+	}
+	fields := positionalFields(v.Elem())
+	if len(values) > len(fields) {
+		return fmt.Errorf("Too many params by position, expected at most %d", len(fields))
+	}
+	for i, value := range values {
+		if err := json.Unmarshal(value, fields[i].Addr().Interface()); err != nil {
+			return fmt.Errorf("Can't unmarshal param %d: %s", i, err.Error())
+		}
+	}
+	return nil
+}
+
+func positionalFields(v reflect.Value) []reflect.Value {
+	t := v.Type()
+	fields := make([]reflect.Value, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && strings.Split(tag, ",")[0] == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fv := v.Field(i)
+				if fv.Kind() == reflect.Ptr {
+					if !fv.CanSet() {
+						continue
+					}
+					if fv.IsNil() {
+						fv.Set(reflect.New(ft))
+					}
+					fv = fv.Elem()
+				}
+				fields = append(fields, positionalFields(fv)...)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		fields = append(fields, v.Field(i))
+	}
+	return fields
+}
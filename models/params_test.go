@@ -0,0 +1,124 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type paramsBase struct {
+	Id int `json:"id"`
+}
+
+type paramsArgs struct {
+	paramsBase
+	Name    string `json:"name"`
+	Ignored string `json:"-"`
+	hidden  string
+	Tags    []string `json:"tags"`
+}
+
+func TestUnmarshalParams_ByPosition(t *testing.T) {
+	a := assert.New(t)
+	var args paramsArgs
+	a.NoError(UnmarshalParams(json.RawMessage(`[1, "x", ["a"]]`), &args))
+	a.Equal(1, args.Id)
+	a.Equal("x", args.Name)
+	a.Equal([]string{"a"}, args.Tags)
+
+	args = paramsArgs{}
+	a.NoError(UnmarshalParams(json.RawMessage(` [2]`), &args))
+	a.Equal(2, args.Id)
+	a.Empty(args.Name)
+}
+
+func TestUnmarshalParams_ByName(t *testing.T) {
+	a := assert.New(t)
+	var args paramsArgs
+	a.NoError(UnmarshalParams(json.RawMessage(`{"id":1,"name":"x"}`), &args))
+	a.Equal(1, args.Id)
+	a.Equal("x", args.Name)
+}
+
+func TestUnmarshalParams_Errors(t *testing.T) {
+	a := assert.New(t)
+	var args paramsArgs
+	a.Error(UnmarshalParams(json.RawMessage(`[1, "x", [], 4]`), &args))
+	a.Error(UnmarshalParams(json.RawMessage(`["1"]`), &args))
+
+	var list []int
+	a.NoError(UnmarshalParams(json.RawMessage(`[1, 2]`), &list))
+	a.Equal([]int{1, 2}, list)
+}
+
+type strictArgs struct {
+	Id   int    `json:"id" jrpc2hh:"required"`
+	Name string `json:"name"`
+}
+
+func TestDecodeParams(t *testing.T) {
+	a := assert.New(t)
+	raw := func(s string) *json.RawMessage {
+		r := json.RawMessage(s)
+		return &r
+	}
+
+	var args strictArgs
+	a.Nil(DecodeParams(raw(`{"id":1,"name":"x"}`), &args, ParamsOptions{DisallowUnknownFields: true}))
+	a.Equal(strictArgs{1, "x"}, args)
+	a.Nil(DecodeParams(raw(`[1]`), &args, ParamsOptions{Required: true}))
+
+	jErr := DecodeParams(nil, &args, ParamsOptions{Required: true})
+	a.NotNil(jErr)
+	a.Equal(ErrorCodeInvalidParams, jErr.Code)
+
+	jErr = DecodeParams(nil, &args, ParamsOptions{})
+	a.NotNil(jErr)
+	a.Equal(ParamsErrorData{Missing: []string{"id"}}, jErr.Data)
+
+	jErr = DecodeParams(raw(`{"nmae":"x","extra":1}`), &args, ParamsOptions{DisallowUnknownFields: true, RequiredFields: []string{"name"}})
+	a.NotNil(jErr)
+	a.Equal(ErrorCodeInvalidParams, jErr.Code)
+	a.Equal(ParamsErrorData{Unknown: []string{"extra", "nmae"}, Missing: []string{"id", "name"}}, jErr.Data)
+
+	jErr = DecodeParams(raw(`{"ID":1,"extra":1}`), &args, ParamsOptions{})
+	a.Nil(jErr)
+
+	jErr = DecodeParams(raw(`["x"]`), &args, ParamsOptions{})
+	a.NotNil(jErr)
+	a.Equal(ErrorCodeInvalidParams, jErr.Code)
+}
+
+type nestedAddr struct {
+	City string `json:"city"`
+}
+
+type nestedArgs struct {
+	Addr  *nestedAddr           `json:"addr"`
+	Items []nestedAddr          `json:"items"`
+	Named map[string]nestedAddr `json:"named"`
+	Raw   json.RawMessage       `json:"raw"`
+}
+
+func TestDecodeParams_NestedUnknown(t *testing.T) {
+	a := assert.New(t)
+	raw := func(s string) *json.RawMessage {
+		r := json.RawMessage(s)
+		return &r
+	}
+	opts := ParamsOptions{DisallowUnknownFields: true}
+
+	var args nestedArgs
+	a.Nil(DecodeParams(raw(`{"addr":{"city":"x"},"items":[{"City":"y"}],"named":{"a":{"city":"z"}},"raw":{"any":1}}`), &args, opts))
+	a.Nil(DecodeParams(raw(`{"addr":null}`), &args, opts))
+
+	jErr := DecodeParams(raw(`{"addr":{"ctiy":"x"},"items":[{"city":"y"},{"cty":"y"}],"named":{"a":{"x":1}}}`), &args, opts)
+	a.NotNil(jErr)
+	a.Equal(ParamsErrorData{Unknown: []string{"addr.ctiy", "items[1].cty", "named.a.x"}, Missing: []string{}}, jErr.Data)
+
+	jErr = DecodeParams(raw(`[{"ctiy":"x"}]`), &args, opts)
+	a.NotNil(jErr)
+	a.Equal(ParamsErrorData{Unknown: []string{"addr.ctiy"}, Missing: []string{}}, jErr.Data)
+
+	a.Nil(DecodeParams(raw(`{"addr":{"ctiy":"x"}}`), &args, ParamsOptions{}))
+}
@@ -1,46 +1,104 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"strings"
-	"unicode/utf8"
 )
 
 type RequestBody struct {
 	JsonRpc string           `json:"jsonrpc"`
 	Method  string           `json:"method"`
-	Id      *interface{}     `json:"id"`
+	Id      *interface{}     `json:"id,omitempty"`
 	Params  *json.RawMessage `json:"params,omitempty"`
+
+	// service and method are set by router of handler
+	service string
+	method  string
+	routed  bool
+	// hasId is true if unmarshalled request contains id, even if it is null
+	hasId bool
+}
+
+// UnmarshalJSON remembers presence of id, request with null id isn't notification
+func (r *RequestBody) UnmarshalJSON(data []byte) error {
+	type plain RequestBody
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	_, r.hasId = fields["id"]
+	return nil
 }
 
 func (r *RequestBody) Validate() error {
 	if r.JsonRpc != "2.0" {
-		return errors.New("Bad request, field 'jsonrpc' is empty")
+		return errors.New("Bad request, field 'jsonrpc' must be '2.0'")
 	}
 
-	dotI := strings.Index(r.Method, ".")
-	if dotI < 0 || strings.Count(r.Method, ".") != 1 ||
-		dotI == 0 || dotI == (utf8.RuneCountInString(r.Method)-1) {
-		return errors.New("Bad request, bad format field 'method'")
+	if r.Method == "" {
+		return errors.New("Bad request, field 'method' is empty")
 	}
 
-	if r.Id == nil {
-		return errors.New("Bad request, bad format field 'id'")
+	if !r.HasValidId() {
+		return errors.New("Bad request, field 'id' must be string, number or null")
+	}
+
+	if r.Params != nil {
+		if p := bytes.TrimLeft(*r.Params, " \t\r\n"); len(p) == 0 || (p[0] != '{' && p[0] != '[') {
+			return errors.New("Bad request, field 'params' must be object or array")
+		}
 	}
 
 	return nil
 }
 
+// HasValidId returns true if id is string, number or absent
+func (r *RequestBody) HasValidId() bool {
+	if r.Id == nil {
+		return true
+	}
+	switch (*r.Id).(type) {
+	case string, float64, json.Number, int, int64:
+		return true
+	}
+	return false
+}
+
+// IsNotification returns true for requests without id, server must not reply to them
+func (r *RequestBody) IsNotification() bool {
+	return r.Id == nil && !r.hasId
+}
+
+// SetRoute sets name of service and name of method in service resolved from field 'method'
+func (r *RequestBody) SetRoute(service string, method string) {
+	r.service = service
+	r.method = method
+	r.routed = true
+}
+
+// GetService returns name of service, it is part of 'method' before last dot if request isn't routed
 func (r *RequestBody) GetService() string {
-	s := strings.Split(r.Method, ".")
-	return s[0]
+	if r.routed {
+		return r.service
+	}
+	if i := strings.LastIndex(r.Method, "."); i >= 0 {
+		return r.Method[:i]
+	}
+	return ""
 }
 
+// GetMethod returns name of method in service, it is part of 'method' after last dot if request isn't routed
 func (r *RequestBody) GetMethod() string {
-	s := strings.Split(r.Method, ".")
-	return s[1]
+	if r.routed {
+		return r.method
+	}
+	return r.Method[strings.LastIndex(r.Method, ".")+1:]
 }
 
 func (r *RequestBody) HasParams() bool {
@@ -0,0 +1,323 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Rules of tag, e.g. `jrpc2hh:"required,min=1,max=10"`, min, max and len limit value of number
+// or length of string, slice and map
+const (
+	RuleRequired = "required"
+	RuleMin      = "min"
+	RuleMax      = "max"
+	RuleLen      = "len"
+	// RuleRegexp must be the last rule of tag, because expression can contain commas
+	RuleRegexp = "regexp"
+	// RuleEnum lists allowed values separated by '|'
+	RuleEnum  = "enum"
+	RuleEmail = "email"
+	RuleUUID  = "uuid"
+)
+
+var uuidRegexp = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// rulesCache contains parsed rules by tag
+var rulesCache sync.Map
+
+type Rule struct {
+	Name  string
+	Value string
+	num   float64
+	re    *regexp.Regexp
+}
+
+// FieldError describes rule failed by field of args
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ParseRules parses rules of tag, unknown rules and bad values of rules are errors
+func ParseRules(tag string) ([]Rule, error) {
+	rules := make([]Rule, 0)
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, RuleRegexp+"=") {
+			part, tag = tag, ""
+		} else if i := strings.Index(tag, ","); i >= 0 {
+			part, tag = tag[:i], tag[i+1:]
+		} else {
+			part, tag = tag, ""
+		}
+		kv := strings.SplitN(part, "=", 2)
+		r := Rule{Name: kv[0]}
+		if len(kv) == 2 {
+			r.Value = kv[1]
+		}
+		switch r.Name {
+		case RuleRequired, RuleEmail, RuleUUID:
+			if len(kv) == 2 {
+				return nil, fmt.Errorf("Rule '%s' can't have value", r.Name)
+			}
+		case RuleMin, RuleMax, RuleLen:
+			num, err := strconv.ParseFloat(r.Value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Rule '%s' must have number value", r.Name)
+			}
+			r.num = num
+		case RuleRegexp:
+			re, err := regexp.Compile(r.Value)
+			if err != nil {
+				return nil, fmt.Errorf("Rule '%s' has bad expression: %s", r.Name, err.Error())
+			}
+			r.re = re
+		case RuleEnum:
+			if r.Value == "" {
+				return nil, fmt.Errorf("Rule '%s' must have values", r.Name)
+			}
+		default:
+			return nil, fmt.Errorf("Unknown rule '%s'", part)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func cachedRules(tag string) ([]Rule, error) {
+	if rules, ok := rulesCache.Load(tag); ok {
+		return rules.([]Rule), nil
+	}
+	rules, err := ParseRules(tag)
+	if err != nil {
+		return nil, err
+	}
+	rulesCache.Store(tag, rules)
+	return rules, nil
+}
+
+// ValidateArgs checks all fields of args and nested structures with rules of their tags,
+// failed rules are returned as invalid params error with list of FieldError in data
+func ValidateArgs(args interface{}) *Error {
+	return checkArgs(args, nil)
+}
+
+// ValidatePassedArgs checks fields of args like ValidateArgs, but only fields passed in params
+// by name or by position, omitted fields and nulls aren't validated like absent properties
+// aren't validated by schema
+func ValidatePassedArgs(params *json.RawMessage, args interface{}) *Error {
+	raw := json.RawMessage("{}")
+	if params != nil {
+		raw = *params
+	}
+	var values []json.RawMessage
+	fields := paramFields(reflect.TypeOf(args))
+	if p := bytes.TrimLeft(raw, " \t\r\n"); fields != nil && len(p) != 0 && p[0] == '[' && json.Unmarshal(raw, &values) == nil {
+		// params passed by position are mapped to names of fields in the same order as by UnmarshalParams
+		byName := make(map[string]json.RawMessage)
+		for i, f := range fields {
+			if i < len(values) {
+				byName[f.name] = values[i]
+			}
+		}
+		raw, _ = json.Marshal(byName)
+	}
+	return checkArgs(args, raw)
+}
+
+func checkArgs(args interface{}, raw json.RawMessage) *Error {
+	errs, err := validateValue(reflect.ValueOf(args), "", raw, make([]FieldError, 0))
+	if err != nil {
+		return NewError(ErrorCodeInternalError, "Bad rules of args", err.Error())
+	}
+	if len(errs) != 0 {
+		return NewError(ErrorCodeInvalidParams, "Invalid params", errs)
+	}
+	return nil
+}
+
+// validateValue validates value decoded from raw json, all fields are validated if raw is nil
+func validateValue(v reflect.Value, path string, raw json.RawMessage, errs []FieldError) ([]FieldError, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return errs, nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return validateStruct(v, path, raw, errs)
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if raw != nil {
+			json.Unmarshal(raw, &items)
+		}
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			var item json.RawMessage
+			if i < len(items) {
+				item = items[i]
+			}
+			if errs, err = validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), item, errs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return errs, nil
+}
+
+func validateStruct(v reflect.Value, path string, raw json.RawMessage, errs []FieldError) ([]FieldError, error) {
+	// fields are checked for presence only if structure is decoded from json object
+	var byName map[string]json.RawMessage
+	if raw != nil {
+		json.Unmarshal(raw, &byName)
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		var err error
+		if f.Anonymous && name == "" {
+			if errs, err = validateValue(v.Field(i), path, raw, errs); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		var fRaw json.RawMessage
+		if byName != nil {
+			var ok bool
+			if fRaw, ok = paramValue(byName, name); !ok || bytes.Equal(bytes.TrimSpace(fRaw), []byte("null")) {
+				continue
+			}
+		}
+		fPath := name
+		if path != "" {
+			fPath = path + "." + name
+		}
+		rules, err := cachedRules(f.Tag.Get(TagName))
+		if err != nil {
+			return nil, fmt.Errorf("Field '%s': %s", fPath, err.Error())
+		}
+		for _, r := range rules {
+			msg, err := r.check(v.Field(i))
+			if err != nil {
+				return nil, fmt.Errorf("Field '%s': %s", fPath, err.Error())
+			}
+			if msg != "" {
+				errs = append(errs, FieldError{fPath, r.Name, msg})
+			}
+		}
+		if errs, err = validateValue(v.Field(i), fPath, fRaw, errs); err != nil {
+			return nil, err
+		}
+	}
+	return errs, nil
+}
+
+// check returns message if value doesn't match rule, error is returned if rule can't be applied to value
+func (r Rule) check(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		// absence of value is checked by required rule
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	switch r.Name {
+	case RuleRequired:
+		return "", nil
+	case RuleMin, RuleMax, RuleLen:
+		num, isLen, ok := measure(v)
+		if !ok || (r.Name == RuleLen && !isLen) {
+			return "", fmt.Errorf("Rule '%s' can't be applied to %s", r.Name, v.Type())
+		}
+		what := "must be"
+		if isLen {
+			what = "length must be"
+		}
+		switch {
+		case r.Name == RuleMin && num < r.num:
+			return fmt.Sprintf("%s at least %s", what, r.Value), nil
+		case r.Name == RuleMax && num > r.num:
+			return fmt.Sprintf("%s at most %s", what, r.Value), nil
+		case r.Name == RuleLen && num != r.num:
+			return fmt.Sprintf("%s %s", what, r.Value), nil
+		}
+		return "", nil
+	case RuleEnum:
+		s := fmt.Sprint(v.Interface())
+		for _, e := range strings.Split(r.Value, "|") {
+			if s == e {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Replace(r.Value, "|", ", ", -1)), nil
+	}
+
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("Rule '%s' can't be applied to %s", r.Name, v.Type())
+	}
+	s := v.String()
+	switch r.Name {
+	case RuleRegexp:
+		if !r.re.MatchString(s) {
+			return fmt.Sprintf("must match %s", r.Value), nil
+		}
+	case RuleEmail:
+		if !IsEmail(s) {
+			return "must be email", nil
+		}
+	case RuleUUID:
+		if !IsUUID(s) {
+			return "must be uuid", nil
+		}
+	}
+	return "", nil
+}
+
+// IsEmail returns true if s is bare email address without name and angle brackets
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// IsUUID returns true if s is uuid in canonical textual form
+func IsUUID(s string) bool {
+	return uuidRegexp.MatchString(s)
+}
+
+// measure returns value of number or length of string, slice and map
+func measure(v reflect.Value) (float64, bool, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false, true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true, true
+	}
+	return 0, false, false
+}
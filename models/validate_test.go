@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type validateItem struct {
+	Kind string `json:"kind" jrpc2hh:"enum=a|b"`
+}
+
+type validateArgs struct {
+	Age   int            `json:"age" jrpc2hh:"min=18,max=99"`
+	Name  string         `json:"name" jrpc2hh:"required,len=3"`
+	Code  *string        `json:"code" jrpc2hh:"regexp=^[a-z]{1,3}$"`
+	Email string         `json:"email" jrpc2hh:"email"`
+	Id    string         `json:"id" jrpc2hh:"uuid"`
+	Tags  []string       `json:"tags" jrpc2hh:"max=2"`
+	Items []validateItem `json:"items"`
+}
+
+func TestParseRules(t *testing.T) {
+	a := assert.New(t)
+	rules, err := ParseRules("required,min=1,regexp=^[a-z]{1,3}$")
+	a.NoError(err)
+	a.Len(rules, 3)
+	a.Equal("^[a-z]{1,3}$", rules[2].Value)
+
+	for _, tag := range []string{"unknown", "min=x", "required=1", "regexp=[", "enum="} {
+		_, err := ParseRules(tag)
+		a.Error(err, tag)
+	}
+}
+
+func TestValidateArgs(t *testing.T) {
+	a := assert.New(t)
+	code := "abc"
+	args := validateArgs{
+		Age:   20,
+		Name:  "Bob",
+		Code:  &code,
+		Email: "bob@example.com",
+		Id:    "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		Tags:  []string{"a"},
+		Items: []validateItem{{"a"}},
+	}
+	a.Nil(ValidateArgs(&args))
+
+	code = "abcd"
+	args = validateArgs{
+		Age:   10,
+		Name:  "Alice",
+		Code:  &code,
+		Email: "alice",
+		Id:    "1",
+		Tags:  []string{"a", "b", "c"},
+		Items: []validateItem{{"a"}, {"c"}},
+	}
+	jErr := ValidateArgs(&args)
+	a.NotNil(jErr)
+	a.Equal(ErrorCodeInvalidParams, jErr.Code)
+	a.Equal([]FieldError{
+		{"age", "min", "must be at least 18"},
+		{"name", "len", "length must be 3"},
+		{"code", "regexp", "must match ^[a-z]{1,3}$"},
+		{"email", "email", "must be email"},
+		{"id", "uuid", "must be uuid"},
+		{"tags", "max", "length must be at most 2"},
+		{"items[1].kind", "enum", "must be one of a, b"},
+	}, jErr.Data)
+}
+
+func TestValidateArgs_BadRule(t *testing.T) {
+	a := assert.New(t)
+	args := struct {
+		Flag bool `jrpc2hh:"min=1"`
+	}{}
+	jErr := ValidateArgs(&args)
+	a.NotNil(jErr)
+	a.Equal(ErrorCodeInternalError, jErr.Code)
+}
+
+func TestValidatePassedArgs(t *testing.T) {
+	a := assert.New(t)
+	params := func(s string) *json.RawMessage {
+		raw := json.RawMessage(s)
+		return &raw
+	}
+	a.Nil(ValidatePassedArgs(params(`{"name":"Bob"}`), &validateArgs{Name: "Bob"}))
+	a.Nil(ValidatePassedArgs(params(`{"name":"Bob","email":null}`), &validateArgs{Name: "Bob"}))
+	a.Nil(ValidatePassedArgs(nil, &validateArgs{}))
+
+	// explicit zero values are validated
+	jErr := ValidatePassedArgs(params(`{"age":0,"name":"","items":[{},{"kind":""}]}`), &validateArgs{Items: []validateItem{{}, {}}})
+	a.NotNil(jErr)
+	a.Equal([]FieldError{
+		{"age", "min", "must be at least 18"},
+		{"name", "len", "length must be 3"},
+		{"items[1].kind", "enum", "must be one of a, b"},
+	}, jErr.Data)
+
+	// params passed by position
+	jErr = ValidatePassedArgs(params(`[0]`), &validateArgs{})
+	a.NotNil(jErr)
+	a.Equal([]FieldError{{"age", "min", "must be at least 18"}}, jErr.Data)
+}
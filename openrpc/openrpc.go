@@ -0,0 +1,127 @@
+package openrpc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Version of OpenRPC specification used for documents
+const Version = "1.2.6"
+
+type Document struct {
+	OpenRPC    string      `json:"openrpc"`
+	Info       Info        `json:"info"`
+	Methods    []*Method   `json:"methods"`
+	Components *Components `json:"components,omitempty"`
+}
+
+func NewDocument(title string, version string) *Document {
+	return &Document{
+		OpenRPC: Version,
+		Info:    Info{Title: title, Version: version},
+		Methods: make([]*Method, 0),
+	}
+}
+
+// AddSchemas adds schemas to components of document, schemas aren't added if any of them has the same name
+// as different schema of document
+func (d *Document) AddSchemas(schemas map[string]*Schema) error {
+	if len(schemas) == 0 {
+		return nil
+	}
+	if d.Components == nil {
+		d.Components = &Components{Schemas: make(map[string]*Schema)}
+	}
+	for n, s := range schemas {
+		if existing, ok := d.Components.Schemas[n]; ok && !reflect.DeepEqual(existing, s) {
+			return fmt.Errorf("schema '%s' is already used by different schema", n)
+		}
+	}
+	for n, s := range schemas {
+		d.Components.Schemas[n] = s
+	}
+	return nil
+}
+
+// QualifySchemas prefixes names of components and refs to them in methods and schemas of document
+func (d *Document) QualifySchemas(prefix string) {
+	if d.Components == nil {
+		return
+	}
+	schemas := make(map[string]*Schema, len(d.Components.Schemas))
+	for n, s := range d.Components.Schemas {
+		s.qualifyRefs(prefix)
+		schemas[prefix+n] = s
+	}
+	d.Components.Schemas = schemas
+	for _, m := range d.Methods {
+		for _, p := range m.Params {
+			p.Schema.qualifyRefs(prefix)
+		}
+		if m.Result != nil {
+			m.Result.Schema.qualifyRefs(prefix)
+		}
+	}
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Method struct {
+	Name string `json:"name"`
+	// Aliases are names of method accepted in addition to name, it is extension of specification
+	Aliases        []string             `json:"x-aliases,omitempty"`
+	ParamStructure string               `json:"paramStructure,omitempty"`
+	Params         []*ContentDescriptor `json:"params"`
+	Result         *ContentDescriptor   `json:"result"`
+}
+
+type ContentDescriptor struct {
+	Name     string  `json:"name"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema is subset of JSON Schema used for describing params and results
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+}
+
+// qualifyRefs prefixes names of components in refs of schema and nested schemas
+func (s *Schema) qualifyRefs(prefix string) {
+	if s == nil {
+		return
+	}
+	if strings.HasPrefix(s.Ref, componentsRef) {
+		s.Ref = componentsRef + prefix + strings.TrimPrefix(s.Ref, componentsRef)
+	}
+	for _, p := range s.Properties {
+		p.qualifyRefs(prefix)
+	}
+	s.Items.qualifyRefs(prefix)
+	s.AdditionalProperties.qualifyRefs(prefix)
+}
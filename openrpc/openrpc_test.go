@@ -0,0 +1,37 @@
+package openrpc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDocument_AddSchemas(t *testing.T) {
+	a := assert.New(t)
+	doc := NewDocument("", "")
+	a.NoError(doc.AddSchemas(map[string]*Schema{"Item": {Type: "string"}}))
+	a.NoError(doc.AddSchemas(map[string]*Schema{"Item": {Type: "string"}, "Other": {Type: "integer"}}))
+	a.Len(doc.Components.Schemas, 2)
+
+	a.Error(doc.AddSchemas(map[string]*Schema{"Item": {Type: "integer"}, "New": {Type: "integer"}}))
+	a.Equal("string", doc.Components.Schemas["Item"].Type)
+	a.NotContains(doc.Components.Schemas, "New")
+}
+
+func TestDocument_QualifySchemas(t *testing.T) {
+	a := assert.New(t)
+	doc := NewDocument("", "")
+	doc.Methods = append(doc.Methods, &Method{
+		Name:   "Do",
+		Params: []*ContentDescriptor{{Name: "items", Schema: &Schema{Type: "array", Items: &Schema{Ref: componentsRef + "Item"}}}},
+		Result: &ContentDescriptor{Name: "result", Schema: &Schema{Ref: componentsRef + "Item"}},
+	})
+	doc.Components = &Components{Schemas: map[string]*Schema{
+		"Item": {Type: "object", Properties: map[string]*Schema{"next": {Ref: componentsRef + "Item"}}},
+	}}
+
+	doc.QualifySchemas("svc.")
+	a.Equal(componentsRef+"svc.Item", doc.Methods[0].Params[0].Schema.Items.Ref)
+	a.Equal(componentsRef+"svc.Item", doc.Methods[0].Result.Schema.Ref)
+	a.NotContains(doc.Components.Schemas, "Item")
+	a.Equal(componentsRef+"svc.Item", doc.Components.Schemas["svc.Item"].Properties["next"].Ref)
+}
@@ -0,0 +1,291 @@
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/andrskom/jrpc2hh/models"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const componentsRef = "#/components/schemas/"
+
+// patterns contains compiled patterns of schemas
+var patterns sync.Map
+
+// ValidationError describes value which doesn't match schema, path is JSON pointer to value in params
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidateParams validates params of method passed by name or by position with schemas of params,
+// refs are resolved by components
+func (m *Method) ValidateParams(params *json.RawMessage, components *Components) []ValidationError {
+	v := &validator{components: components, errs: make([]ValidationError, 0)}
+	if params == nil {
+		for _, p := range m.Params {
+			if p.Required {
+				v.add("/"+escapePointer(p.Name), "is required")
+			}
+		}
+		return v.errs
+	}
+	value, err := decode(*params)
+	if err != nil {
+		v.add("", "must be JSON")
+		return v.errs
+	}
+
+	// params of types from another packages are described by one descriptor of whole params
+	if len(m.Params) == 1 && m.Params[0].Name == "params" && m.ParamStructure == "" {
+		v.validate("", value, m.Params[0].Schema, 0)
+		return v.errs
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if m.ParamStructure == "by-position" {
+			v.add("", "must be passed by position")
+			return v.errs
+		}
+		for _, p := range m.Params {
+			pValue, ok := value[p.Name]
+			path := "/" + escapePointer(p.Name)
+			if !ok {
+				if p.Required {
+					v.add(path, "is required")
+				}
+				continue
+			}
+			v.validate(path, pValue, p.Schema, 0)
+		}
+	case []interface{}:
+		if m.ParamStructure == "by-name" {
+			v.add("", "must be passed by name")
+			return v.errs
+		}
+		if len(value) > len(m.Params) {
+			v.add("/"+strconv.Itoa(len(m.Params)), fmt.Sprintf("must not be passed, method has %d params", len(m.Params)))
+		}
+		for i, p := range m.Params {
+			path := "/" + strconv.Itoa(i)
+			if i >= len(value) {
+				if p.Required {
+					v.add(path, "is required")
+				}
+				continue
+			}
+			v.validate(path, value[i], p.Schema, 0)
+		}
+	case nil:
+	default:
+		v.add("", "must be object or array")
+	}
+	return v.errs
+}
+
+// Validate validates JSON value with schema, refs are resolved by components
+func (s *Schema) Validate(data []byte, components *Components) []ValidationError {
+	v := &validator{components: components, errs: make([]ValidationError, 0)}
+	value, err := decode(data)
+	if err != nil {
+		v.add("", "must be JSON")
+		return v.errs
+	}
+	v.validate("", value, s, 0)
+	return v.errs
+}
+
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	err := dec.Decode(&value)
+	return value, err
+}
+
+type validator struct {
+	components *Components
+	errs       []ValidationError
+}
+
+func (v *validator) add(path string, msg string) {
+	v.errs = append(v.errs, ValidationError{path, msg})
+}
+
+// maxRefDepth protects from infinite recursion of refs referencing each other without nesting of value
+const maxRefDepth = 32
+
+// validate checks value with schema, null is accepted as absence of value like encoding/json does
+func (v *validator) validate(path string, value interface{}, s *Schema, refDepth int) {
+	if s == nil || value == nil {
+		return
+	}
+	if s.Ref != "" {
+		if ref := v.resolve(s.Ref); ref != nil && refDepth < maxRefDepth {
+			v.validate(path, value, ref, refDepth+1)
+		}
+	}
+	if !v.validateType(path, value, s.Type) {
+		return
+	}
+
+	if len(s.Enum) != 0 && !inEnum(value, s.Enum) {
+		values := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			values = append(values, fmt.Sprint(e))
+		}
+		v.add(path, "must be one of "+strings.Join(values, ", "))
+	}
+
+	switch value := value.(type) {
+	case json.Number:
+		f, _ := value.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			v.add(path, fmt.Sprintf("must be at least %v", *s.Minimum))
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			v.add(path, fmt.Sprintf("must be at most %v", *s.Maximum))
+		}
+	case string:
+		v.validateLength(path, "length", utf8.RuneCountInString(value), s.MinLength, s.MaxLength)
+		if s.Pattern != "" {
+			if re, err := pattern(s.Pattern); err == nil && !re.MatchString(value) {
+				v.add(path, "must match "+s.Pattern)
+			}
+		}
+		v.validateFormat(path, value, s.Format)
+	case []interface{}:
+		v.validateLength(path, "count of items", len(value), s.MinItems, s.MaxItems)
+		for i, item := range value {
+			v.validate(path+"/"+strconv.Itoa(i), item, s.Items, 0)
+		}
+	case map[string]interface{}:
+		v.validateLength(path, "count of properties", len(value), s.MinProperties, s.MaxProperties)
+		for _, n := range s.Required {
+			if _, ok := value[n]; !ok {
+				v.add(path+"/"+escapePointer(n), "is required")
+			}
+		}
+		for n, pValue := range value {
+			pPath := path + "/" + escapePointer(n)
+			if ps, ok := s.Properties[n]; ok {
+				v.validate(pPath, pValue, ps, 0)
+			} else if s.AdditionalProperties != nil {
+				v.validate(pPath, pValue, s.AdditionalProperties, 0)
+			}
+		}
+	}
+}
+
+func (v *validator) resolve(ref string) *Schema {
+	if v.components == nil || !strings.HasPrefix(ref, componentsRef) {
+		return nil
+	}
+	return v.components.Schemas[strings.TrimPrefix(ref, componentsRef)]
+}
+
+func (v *validator) validateType(path string, value interface{}, t string) bool {
+	ok := true
+	switch t {
+	case "":
+		return true
+	case "object":
+		_, ok = value.(map[string]interface{})
+	case "array":
+		_, ok = value.([]interface{})
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "number":
+		_, ok = value.(json.Number)
+	case "integer":
+		var n json.Number
+		if n, ok = value.(json.Number); ok {
+			f, isNumber := new(big.Float).SetString(string(n))
+			ok = isNumber && f.IsInt()
+		}
+	}
+	if !ok {
+		v.add(path, "must be "+t)
+	}
+	return ok
+}
+
+func (v *validator) validateLength(path string, what string, l int, min *int, max *int) {
+	if min != nil && l < *min {
+		v.add(path, fmt.Sprintf("%s must be at least %d", what, *min))
+	}
+	if max != nil && l > *max {
+		v.add(path, fmt.Sprintf("%s must be at most %d", what, *max))
+	}
+}
+
+func (v *validator) validateFormat(path string, value string, format string) {
+	ok := true
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		ok = err == nil
+	case "email":
+		ok = models.IsEmail(value)
+	case "uuid":
+		ok = models.IsUUID(value)
+	}
+	if !ok {
+		v.add(path, "must be "+format)
+	}
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if n, ok := value.(json.Number); ok {
+			if f, err := n.Float64(); err == nil && fmt.Sprint(f) == fmt.Sprint(toFloat(e)) {
+				return true
+			}
+			continue
+		}
+		if value == e {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(e interface{}) interface{} {
+	switch e := e.(type) {
+	case float64:
+		return e
+	case json.Number:
+		if f, err := e.Float64(); err == nil {
+			return f
+		}
+	case int:
+		return float64(e)
+	}
+	return e
+}
+
+func pattern(p string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(p); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(p, re)
+	return re, nil
+}
+
+// escapePointer escapes name for JSON pointer
+func escapePointer(n string) string {
+	return strings.Replace(strings.Replace(n, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,72 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func intP(i int) *int {
+	return &i
+}
+
+func floatP(f float64) *float64 {
+	return &f
+}
+
+func testMethod() (*Method, *Components) {
+	m := &Method{
+		Name:           "Create",
+		ParamStructure: "either",
+		Params: []*ContentDescriptor{
+			{Name: "id", Required: true, Schema: &Schema{Type: "integer", Minimum: floatP(1)}},
+			{Name: "name", Schema: &Schema{Type: "string", MaxLength: intP(3), Pattern: "^[a-z]+$"}},
+			{Name: "item", Schema: &Schema{Ref: componentsRef + "Item"}},
+		},
+	}
+	components := &Components{Schemas: map[string]*Schema{
+		"Item": {
+			Type:     "object",
+			Required: []string{"kind"},
+			Properties: map[string]*Schema{
+				"kind":  {Type: "string", Enum: []interface{}{"a", "b"}},
+				"email": {Type: "string", Format: "email"},
+				"tags":  {Type: "array", MaxItems: intP(1), Items: &Schema{Type: "string"}},
+			},
+		},
+	}}
+	return m, components
+}
+
+func raw(s string) *json.RawMessage {
+	r := json.RawMessage(s)
+	return &r
+}
+
+func TestMethod_ValidateParams(t *testing.T) {
+	a := assert.New(t)
+	m, c := testMethod()
+
+	a.Empty(m.ValidateParams(raw(`{"id":1,"name":"abc","item":{"kind":"a","tags":["x"]}}`), c))
+	a.Empty(m.ValidateParams(raw(`[1, "abc", {"kind":"b"}]`), c))
+	a.Empty(m.ValidateParams(raw(`{"id":1,"name":null}`), c))
+
+	a.Equal([]ValidationError{{"/id", "is required"}}, m.ValidateParams(nil, c))
+	a.Equal([]ValidationError{
+		{"/id", "must be integer"},
+		{"/name", "length must be at most 3"},
+		{"/name", "must match ^[a-z]+$"},
+	}, m.ValidateParams(raw(`{"id":1.5,"name":"ABCD"}`), c))
+	a.Equal([]ValidationError{
+		{"/0", "must be at least 1"},
+		{"/2/kind", "must be one of a, b"},
+	}, m.ValidateParams(raw(`[0, "a", {"kind":"c"}]`), c))
+	a.ElementsMatch([]ValidationError{
+		{"/item/kind", "is required"},
+		{"/item/email", "must be email"},
+		{"/item/tags", "count of items must be at most 1"},
+		{"/item/tags/1", "must be string"},
+	}, m.ValidateParams(raw(`{"id":1,"item":{"email":"x","tags":["a",1]}}`), c))
+	a.Equal([]ValidationError{{"/3", "must not be passed, method has 3 params"}}, m.ValidateParams(raw(`[1, "a", null, 4]`), c))
+	a.Equal([]ValidationError{{"", "must be object or array"}}, m.ValidateParams(raw(`"x"`), c))
+}
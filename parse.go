@@ -0,0 +1,440 @@
+package main
+
+import (
+	"fmt"
+	"github.com/andrskom/jrpc2hh/gen/diag"
+	"github.com/andrskom/jrpc2hh/gen/imports"
+	"github.com/andrskom/jrpc2hh/gen/method"
+	"github.com/andrskom/jrpc2hh/gen/service"
+	"github.com/andrskom/jrpc2hh/models"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/packages"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// loadPackages loads packages of services matched by pattern with type information, test packages are skipped.
+// Type errors aren't fatal here, because previously generated files loaded with package can be stale,
+// problems with types of services are reported by parse.
+func loadPackages(fs *token.FileSet, pattern string) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Fset: fs,
+	}
+	// directory without ./ prefix is treated by go list as import path
+	if !filepath.IsAbs(pattern) && !strings.HasPrefix(pattern, ".") {
+		pattern = "./" + pattern
+	}
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			if e.Kind != packages.TypeError {
+				return nil, e
+			}
+		}
+		if len(pkg.GoFiles) == 0 || strings.HasSuffix(pkg.Name, "_test") {
+			continue
+		}
+		res = append(res, pkg)
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("No packages matched by '%s'", pattern)
+	}
+	return res, nil
+}
+
+// packageDir returns dir of package relative to working dir if it is possible
+func packageDir(pkg *packages.Package) string {
+	dir := filepath.Dir(pkg.GoFiles[0])
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return dir
+}
+
+func parse(regExpService *regexp.Regexp, regExpMethod *regexp.Regexp, regExpMethodWithContext *regexp.Regexp, pkg *packages.Package, d *diag.Diagnostics) (*imports.ImportMap, service.ServiceList, method.MethodList) {
+	iMap := imports.NewImportMap()
+	sl := make(service.ServiceList)
+	ml := make(method.MethodList)
+
+	for _, f := range pkg.Syntax {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				// collect services
+				if decl.Tok == token.TYPE && docHasMatch(regExpService, decl.Doc) {
+					cm, text := docAnnotation(regExpService, decl.Doc)
+					parseService(decl, cm, text, sl, d)
+				}
+			case *ast.FuncDecl:
+				// collect methods
+				if docHasMatch(regExpMethod, decl.Doc) {
+					argsWithContext := docHasMatch(regExpMethodWithContext, decl.Doc)
+					sn, m, ok := parseMethod(decl, pkg, argsWithContext, d)
+					if !ok {
+						continue
+					}
+					cm, text := docAnnotation(regExpMethod, decl.Doc)
+					options, ok := parseOptions(cm, strings.TrimPrefix(text, ":withContext"), d, "name", "alias", "params", "unknownFields", "required")
+					if !ok || !parseParamsOptions(cm, options, m, d) {
+						continue
+					}
+					if len(options["name"]) != 0 || len(options["alias"]) != 0 {
+						n := m.Name
+						if len(options["name"]) != 0 {
+							n = options["name"][0]
+						}
+						m.SetWireName(n, options["alias"])
+					}
+					if !checkWireNames(decl, sn, m, ml, d) {
+						continue
+					}
+					for _, s := range []*method.Struct{m.Args, m.Result} {
+						if s.Pack != "" {
+							iMap.Register(s.Pack)
+						}
+					}
+					ml.Add(sn, m)
+				}
+			}
+		}
+	}
+
+	return iMap, sl, ml
+}
+
+func parseService(gd *ast.GenDecl, cm *ast.Comment, text string, sl service.ServiceList, d *diag.Diagnostics) {
+	if len(gd.Specs) != 1 {
+		d.Add(gd.Pos(), "Annotated declaration must contain exactly one type")
+		return
+	}
+	spec := gd.Specs[0].(*ast.TypeSpec)
+	if err := sl.Add(spec.Name.Name); err != nil {
+		d.Add(spec.Pos(), "%s", err)
+		return
+	}
+	options, ok := parseOptions(cm, text, d, "name")
+	if ok && len(options["name"]) != 0 {
+		sl[spec.Name.Name].SetWireName(options["name"][0])
+	}
+}
+
+// parseOptions parses options of annotation in format 'key=value', value can be list separated by comma
+func parseOptions(cm *ast.Comment, text string, d *diag.Diagnostics, keys ...string) (map[string][]string, bool) {
+	options := make(map[string][]string)
+	if text != "" && !unicode.IsSpace(rune(text[0])) {
+		d.Add(cm.Pos(), "Unknown annotation '%s'", strings.TrimPrefix(cm.Text, "//"))
+		return nil, false
+	}
+	ok := true
+	for _, field := range strings.Fields(text) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 || !models.Contains(keys, kv[0]) {
+			d.Add(cm.Pos(), "Unknown option '%s' of annotation, expected %s=value", field, strings.Join(keys, "=value, "))
+			ok = false
+			continue
+		}
+		for _, v := range strings.Split(kv[1], ",") {
+			if v == "" {
+				d.Add(cm.Pos(), "Empty value of option '%s'", kv[0])
+				ok = false
+				continue
+			}
+			options[kv[0]] = append(options[kv[0]], v)
+		}
+		if kv[0] == "name" && len(options[kv[0]]) > 1 {
+			d.Add(cm.Pos(), "Option 'name' must have only one value")
+			ok = false
+		}
+	}
+	return options, ok
+}
+
+// parseParamsOptions sets options of params decoding: params=required, unknownFields=disallow, required=field1,field2
+func parseParamsOptions(cm *ast.Comment, options map[string][]string, m *method.Method, d *diag.Diagnostics) bool {
+	var p method.Params
+	ok := true
+	for _, kv := range [][2]string{{"params", "required"}, {"unknownFields", "disallow"}} {
+		for _, v := range options[kv[0]] {
+			if v != kv[1] {
+				d.Add(cm.Pos(), "Bad value '%s' of option '%s', expected '%s'", v, kv[0], kv[1])
+				ok = false
+			}
+		}
+	}
+	p.Required = len(options["params"]) != 0
+	p.DisallowUnknownFields = len(options["unknownFields"]) != 0
+	p.RequiredFields = options["required"]
+	if (p.Required || p.DisallowUnknownFields || len(p.RequiredFields) != 0) &&
+		m.Args.Pack+m.Args.Name == "github.com/andrskom/jrpc2hh/modelsNilArgs" {
+		d.Add(cm.Pos(), "Options of params can't be used for method '%s' without args", m.Name)
+		ok = false
+	}
+	m.SetParams(p)
+	return ok
+}
+
+// checkWireNames verifies that names of method don't collide with names of another methods of service
+func checkWireNames(fd *ast.FuncDecl, sn string, m *method.Method, ml method.MethodList, d *diag.Diagnostics) bool {
+	used := make(map[string]string)
+	for _, sm := range ml[sn] {
+		for _, n := range sm.WireNames() {
+			used[n] = sm.Name
+		}
+	}
+	ok := true
+	for _, n := range m.WireNames() {
+		if mN, found := used[n]; found {
+			d.Add(fd.Pos(), "Name '%s' of method '%s' is already used by method '%s' of service '%s'", n, m.Name, mN, sn)
+			ok = false
+		}
+		used[n] = m.Name
+	}
+	return ok
+}
+
+// parseMethod returns name of service and method, problems are added to diagnostics
+func parseMethod(fd *ast.FuncDecl, pkg *packages.Package, argsWithContext bool, d *diag.Diagnostics) (string, *method.Method, bool) {
+	mN := fd.Name.Name
+	obj, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func)
+	if !ok {
+		d.Add(fd.Pos(), "Can't resolve type of method '%s'", mN)
+		return "", nil, false
+	}
+	sig := obj.Type().(*types.Signature)
+	if sig.Recv() == nil {
+		d.Add(fd.Pos(), "Annotated function '%s' must be method of service", mN)
+		return "", nil, false
+	}
+	recv, ok := sig.Recv().Type().(*types.Pointer)
+	if !ok {
+		d.Add(fd.Recv.Pos(), "Receiver of method '%s' must be pointer", mN)
+		return "", nil, false
+	}
+	named, ok := recv.Elem().(*types.Named)
+	if !ok {
+		d.Add(fd.Recv.Pos(), "Receiver of method '%s' must be pointer to type of service", mN)
+		return "", nil, false
+	}
+	assType := named.Obj().Name()
+
+	params := make([]*types.Var, 0, sig.Params().Len())
+	for i := 0; i < sig.Params().Len(); i++ {
+		params = append(params, sig.Params().At(i))
+	}
+	withCtx := len(params) != 0 && isContextType(params[0].Type())
+	if withCtx {
+		params = params[1:]
+	}
+	results := sig.Results()
+
+	// method in style func(ctx context.Context, args A) (R, error)
+	if results.Len() == 2 && isErrorType(results.At(1).Type()) {
+		if len(params) != 1 {
+			d.Add(fd.Type.Params.Pos(), "Method '%s' returning result must have params (args A) or (ctx context.Context, args A)", mN)
+			return "", nil, false
+		}
+		args, okArgs := structFromType(params[0].Type(), pkg, params[0].Pos(), d)
+		res, okRes := structFromType(results.At(0).Type(), pkg, fd.Type.Results.Pos(), d)
+		if !okArgs || !okRes || !checkRules(params[0].Type(), params[0].Pos(), make(map[types.Type]bool), d) || !checkArgsWithContext(params[0], argsWithContext, mN, d) {
+			return "", nil, false
+		}
+		m := method.NewMethod(mN, args, res, argsWithContext, withCtx)
+		m.SetReturnResult()
+		return assType, m, true
+	}
+
+	if len(params) != 2 {
+		d.Add(fd.Type.Params.Pos(), "Method '%s' must have params (args A, res *R) or (ctx context.Context, args A, res *R)", mN)
+		return "", nil, false
+	}
+	if results.Len() != 1 || !isErrorType(results.At(0).Type()) {
+		d.Add(fd.Type.Pos(), "Method '%s' must return error or (R, error)", mN)
+		return "", nil, false
+	}
+	if _, ok := params[0].Type().(*types.Pointer); ok {
+		d.Add(params[0].Pos(), "Args of method '%s' must not be pointer", mN)
+		return "", nil, false
+	}
+	args, okArgs := structFromType(params[0].Type(), pkg, params[0].Pos(), d)
+	resPtr, ok := params[1].Type().(*types.Pointer)
+	if !ok {
+		d.Add(params[1].Pos(), "Result of method '%s' must be pointer", mN)
+		return "", nil, false
+	}
+	res, okRes := structFromType(resPtr.Elem(), pkg, params[1].Pos(), d)
+	if !okArgs || !okRes || !checkRules(params[0].Type(), params[0].Pos(), make(map[types.Type]bool), d) || !checkArgsWithContext(params[0], argsWithContext, mN, d) {
+		return "", nil, false
+	}
+	return assType, method.NewMethod(mN, args, res, argsWithContext, withCtx), true
+}
+
+// checkRules verifies rules in tags of fields of args and nested structures, so bad rules don't fail in runtime
+func checkRules(t types.Type, pos token.Pos, visited map[types.Type]bool, d *diag.Diagnostics) bool {
+	if visited[t] {
+		return true
+	}
+	visited[t] = true
+	switch tt := t.(type) {
+	case *types.Pointer:
+		return checkRules(tt.Elem(), pos, visited, d)
+	case *types.Slice:
+		return checkRules(tt.Elem(), pos, visited, d)
+	case *types.Array:
+		return checkRules(tt.Elem(), pos, visited, d)
+	case *types.Named, *types.Alias:
+		return checkRules(tt.Underlying(), pos, visited, d)
+	case *types.Struct:
+		ok := true
+		for i := 0; i < tt.NumFields(); i++ {
+			f := tt.Field(i)
+			fPos := f.Pos()
+			if !fPos.IsValid() {
+				fPos = pos
+			}
+			rules, err := models.ParseRules(reflect.StructTag(tt.Tag(i)).Get(models.TagName))
+			if err != nil {
+				d.Add(fPos, "Bad rules of field '%s': %s", f.Name(), err.Error())
+				ok = false
+			}
+			for _, r := range rules {
+				if !ruleFitsType(r, f.Type()) {
+					d.Add(fPos, "Bad rules of field '%s': Rule '%s' can't be applied to %s", f.Name(), r.Name, f.Type())
+					ok = false
+				}
+			}
+			ok = checkRules(f.Type(), pos, visited, d) && ok
+		}
+		return ok
+	}
+	return true
+}
+
+// ruleFitsType returns true if rule can be applied to value of type like models.ValidateArgs does,
+// value of interface type is known only in runtime
+func ruleFitsType(r models.Rule, t types.Type) bool {
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = p.Elem()
+	}
+	if _, ok := t.Underlying().(*types.Interface); ok {
+		return true
+	}
+	basic, isBasic := t.Underlying().(*types.Basic)
+	isString := isBasic && basic.Info()&types.IsString != 0
+	switch r.Name {
+	case models.RuleMin, models.RuleMax:
+		if isBasic && basic.Info()&(types.IsInteger|types.IsFloat) != 0 {
+			return true
+		}
+		fallthrough
+	case models.RuleLen:
+		switch t.Underlying().(type) {
+		case *types.Slice, *types.Array, *types.Map:
+			return true
+		}
+		return isString
+	case models.RuleRegexp, models.RuleEmail, models.RuleUUID:
+		return isString
+	}
+	return true
+}
+
+// checkArgsWithContext verifies that args of jrpc2hh:method:withContext can receive context
+func checkArgsWithContext(args *types.Var, argsWithContext bool, mN string, d *diag.Diagnostics) bool {
+	if !argsWithContext {
+		return true
+	}
+	t := args.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	ms := types.NewMethodSet(types.NewPointer(t))
+	if ms.Lookup(nil, "WithContext") == nil {
+		d.Add(args.Pos(), "Args of method '%s' must have method WithContext(context.Context)", mN)
+		return false
+	}
+	return true
+}
+
+// structFromType supports named and basic types and pointers to them
+func structFromType(t types.Type, pkg *packages.Package, pos token.Pos, d *diag.Diagnostics) (*method.Struct, bool) {
+	prefix := ""
+	if p, ok := t.(*types.Pointer); ok {
+		prefix = "*"
+		t = p.Elem()
+	}
+	var s *method.Struct
+	switch t := types.Unalias(t).(type) {
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() == nil {
+			d.Add(pos, "Unsupported type '%s' of args or result", obj.Name())
+			return nil, false
+		}
+		pack := obj.Pkg().Path()
+		if pack == pkg.PkgPath {
+			pack = ""
+		}
+		s = method.NewStruct(pack, obj.Name())
+	case *types.Basic:
+		if t.Kind() == types.Invalid {
+			d.Add(pos, "Unknown type of args or result")
+			return nil, false
+		}
+		s = method.NewStruct("", t.Name())
+	default:
+		d.Add(pos, "Unsupported type '%s' of args or result", t.String())
+		return nil, false
+	}
+	s.SetPrefix(prefix)
+	s.SetType(t)
+	return s, true
+}
+
+func isContextType(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+func isErrorType(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// docAnnotation returns comment containing annotation and text of comment after annotation
+func docAnnotation(regexp *regexp.Regexp, doc *ast.CommentGroup) (*ast.Comment, string) {
+	for _, cm := range doc.List {
+		if loc := regexp.FindStringIndex(cm.Text); loc != nil {
+			return cm, cm.Text[loc[1]:]
+		}
+	}
+	return nil, ""
+}
+
+func docHasMatch(regexp *regexp.Regexp, doc *ast.CommentGroup) bool {
+	res := false
+	if doc != nil {
+		for _, cm := range doc.List {
+			if regexp.Match([]byte(cm.Text)) {
+				res = true
+			}
+		}
+	}
+
+	return res
+}
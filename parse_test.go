@@ -0,0 +1,254 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/andrskom/jrpc2hh/gen/diag"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/packages"
+)
+
+const parseHeader = `package test
+
+import "context"
+
+// jrpc2hh:service
+type Svc struct{}
+
+type Args struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type Res struct{}
+
+type CtxArgs struct {
+	ctx context.Context
+}
+
+func (a *CtxArgs) WithContext(ctx context.Context) {
+	a.ctx = ctx
+}
+
+`
+
+// parseSource parses and type-checks source of package like it is loaded by go/packages
+func parseSource(t *testing.T, src string) *diag.Diagnostics {
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, "/test/test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object), Types: make(map[ast.Expr]types.TypeAndValue)}
+	tPkg, err := (&types.Config{Importer: importer.Default()}).Check("example.com/test", fs, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packages.Package{
+		Name:      "test",
+		PkgPath:   "example.com/test",
+		GoFiles:   []string{"/test/test.go"},
+		Syntax:    []*ast.File{f},
+		Types:     tPkg,
+		TypesInfo: info,
+		Fset:      fs,
+	}
+	d := diag.NewDiagnostics(fs)
+	parsePackages([]*packages.Package{pkg}, d)
+	return d
+}
+
+func TestParse_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		messages []string
+	}{
+		{
+			name: "valid methods",
+			src: `// jrpc2hh:method name=get alias=Get,fetch params=required unknownFields=disallow required=name
+func (s *Svc) Get(args Args, res *Res) error { return nil }
+
+// jrpc2hh:method
+func (s *Svc) Find(ctx context.Context, args Args) (*Res, error) { return nil, nil }
+
+// jrpc2hh:method:withContext
+func (s *Svc) Create(args CtxArgs, res *Res) error { return nil }
+`,
+		},
+		{
+			name: "receiver is not pointer",
+			src: `// jrpc2hh:method
+func (s Svc) Get(args Args, res *Res) error { return nil }
+`,
+			messages: []string{"Receiver of method 'Get' must be pointer"},
+		},
+		{
+			name: "function is not method",
+			src: `// jrpc2hh:method
+func Get(args Args, res *Res) error { return nil }
+`,
+			messages: []string{"Annotated function 'Get' must be method of service"},
+		},
+		{
+			name: "no result param",
+			src: `// jrpc2hh:method
+func (s *Svc) Get(args Args) error { return nil }
+`,
+			messages: []string{"Method 'Get' must have params (args A, res *R) or (ctx context.Context, args A, res *R)"},
+		},
+		{
+			name: "non-context first argument",
+			src: `// jrpc2hh:method
+func (s *Svc) Get(id int, args Args, res *Res) error { return nil }
+`,
+			messages: []string{"Method 'Get' must have params (args A, res *R) or (ctx context.Context, args A, res *R)"},
+		},
+		{
+			name: "non-context first argument of method returning result",
+			src: `// jrpc2hh:method
+func (s *Svc) Get(id int, args Args) (*Res, error) { return nil, nil }
+`,
+			messages: []string{"Method 'Get' returning result must have params (args A) or (ctx context.Context, args A)"},
+		},
+		{
+			name: "result is not error",
+			src: `// jrpc2hh:method
+func (s *Svc) Get(args Args, res *Res) bool { return true }
+`,
+			messages: []string{"Method 'Get' must return error or (R, error)"},
+		},
+		{
+			name: "args are pointer",
+			src: `// jrpc2hh:method
+func (s *Svc) Get(args *Args, res *Res) error { return nil }
+`,
+			messages: []string{"Args of method 'Get' must not be pointer"},
+		},
+		{
+			name: "result is not pointer",
+			src: `// jrpc2hh:method
+func (s *Svc) Get(args Args, res Res) error { return nil }
+`,
+			messages: []string{"Result of method 'Get' must be pointer"},
+		},
+		{
+			name: "args without WithContext",
+			src: `// jrpc2hh:method:withContext
+func (s *Svc) Get(args Args, res *Res) error { return nil }
+`,
+			messages: []string{"Args of method 'Get' must have method WithContext(context.Context)"},
+		},
+		{
+			name: "bad rules",
+			src: `type RuleArgs struct {
+	Name string ` + "`jrpc2hh:\"min=x\"`" + `
+}
+
+// jrpc2hh:method
+func (s *Svc) Get(args RuleArgs, res *Res) error { return nil }
+`,
+			messages: []string{"Bad rules of field 'Name': Rule 'min' must have number value"},
+		},
+		{
+			name: "rules fitting types",
+			src: `type Code string
+
+type TypedArgs struct {
+	Age   *int              ` + "`jrpc2hh:\"min=18,max=99\"`" + `
+	Tags  []string          ` + "`jrpc2hh:\"len=2\"`" + `
+	Meta  map[string]string ` + "`jrpc2hh:\"max=3\"`" + `
+	Code  Code              ` + "`jrpc2hh:\"regexp=^[a-z]+$\"`" + `
+	Email *string           ` + "`jrpc2hh:\"email\"`" + `
+	Any   interface{}       ` + "`jrpc2hh:\"uuid\"`" + `
+}
+
+// jrpc2hh:method
+func (s *Svc) Get(args TypedArgs, res *Res) error { return nil }
+`,
+		},
+		{
+			name: "rules not fitting types",
+			src: `type TypedArgs struct {
+	Flag  bool  ` + "`jrpc2hh:\"min=1\"`" + `
+	Count int   ` + "`jrpc2hh:\"email\"`" + `
+	Size  int64 ` + "`jrpc2hh:\"len=2\"`" + `
+}
+
+// jrpc2hh:method
+func (s *Svc) Get(args TypedArgs, res *Res) error { return nil }
+`,
+			messages: []string{
+				"Bad rules of field 'Flag': Rule 'min' can't be applied to bool",
+				"Bad rules of field 'Count': Rule 'email' can't be applied to int",
+				"Bad rules of field 'Size': Rule 'len' can't be applied to int64",
+			},
+		},
+		{
+			name: "unknown option",
+			src: `// jrpc2hh:method title=get
+func (s *Svc) Get(args Args, res *Res) error { return nil }
+`,
+			messages: []string{"Unknown option 'title=get' of annotation, expected name=value, alias=value, params=value, unknownFields=value, required=value"},
+		},
+		{
+			name: "unknown annotation",
+			src: `// jrpc2hh:methods
+func (s *Svc) Get(args Args, res *Res) error { return nil }
+`,
+			messages: []string{"Unknown annotation ' jrpc2hh:methods'"},
+		},
+		{
+			name: "empty value and several names",
+			src: `// jrpc2hh:method name=get,find alias=
+func (s *Svc) Get(args Args, res *Res) error { return nil }
+`,
+			messages: []string{"Option 'name' must have only one value", "Empty value of option 'alias'"},
+		},
+		{
+			name: "bad values of params options",
+			src: `// jrpc2hh:method params=optional unknownFields=allow
+func (s *Svc) Get(args Args, res *Res) error { return nil }
+`,
+			messages: []string{
+				"Bad value 'optional' of option 'params', expected 'required'",
+				"Bad value 'allow' of option 'unknownFields', expected 'disallow'",
+			},
+		},
+		{
+			name: "duplicate wire name",
+			src: `// jrpc2hh:method name=get
+func (s *Svc) Get(args Args, res *Res) error { return nil }
+
+// jrpc2hh:method alias=get
+func (s *Svc) Find(args Args, res *Res) error { return nil }
+`,
+			messages: []string{"Name 'get' of method 'Find' is already used by method 'Get' of service 'Svc'"},
+		},
+		{
+			name: "unknown option of service",
+			src: `// jrpc2hh:service alias=other
+type Other struct{}
+`,
+			messages: []string{"Unknown option 'alias=other' of annotation, expected name=value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			d := parseSource(t, parseHeader+tt.src)
+			messages := make([]string, 0)
+			for _, dg := range d.List() {
+				messages = append(messages, dg.Message)
+			}
+			if tt.messages == nil {
+				tt.messages = []string{}
+			}
+			a.Equal(tt.messages, messages)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package first
+
+// jrpc2hh:service name=users
+type Users struct{}
+
+type Args struct {
+	Id int64 `json:"id"`
+}
+
+// jrpc2hh:method
+func (s *Users) Get(args Args, res *Args) error {
+	return nil
+}
@@ -0,0 +1,6 @@
+package first
+
+// Args has the same qualified name as args of package multi/first
+type Args struct {
+	Total float64 `json:"total"`
+}
@@ -0,0 +1,15 @@
+package second
+
+import "github.com/andrskom/jrpc2hh/testdata/multi/second/first"
+
+// jrpc2hh:service name=orders
+type Orders struct{}
+
+type Args struct {
+	Number string `json:"number" jrpc2hh:"required"`
+}
+
+// jrpc2hh:method
+func (s *Orders) Get(args Args, res *first.Args) error {
+	return nil
+}
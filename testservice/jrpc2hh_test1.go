@@ -1,15 +1,14 @@
 package testservice
+
 //------------------------------------------------------------------------------//
 // This file was generated by andrskom/jrpc2hh package. Please don't remove it. //
 //------------------------------------------------------------------------------//
 
 import (
 	"fmt"
-	"net/http"
-	json "encoding/json"
 	jModels "github.com/andrskom/jrpc2hh/models"
-	models_7620940177658827552 "models"
-	
+	models "github.com/andrskom/jrpc2hh/testservice/models"
+	"net/http"
 )
 
 // Call method for routing
@@ -24,33 +23,36 @@ func (s *Test1) Call(reqBody *jModels.RequestBody, r *http.Request) (interface{}
 		var res Test1NilArgsResult
 		err := s.NilArgs(args, &res)
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil
 	case "NilResult":
 		var args Test1NilResultArgs
-		if reqBody.HasParams() {
-			err := json.Unmarshal(*reqBody.Params, &args)
-			if err != nil {
-				return nil, jModels.NewError(jModels.ErrorCodeInvalidParams, "Can't unmarshal params to args structure'", err.Error())
-			}
+		if jErr := jModels.DecodeParams(reqBody.Params, &args, jModels.ParamsOptions{Required: true, DisallowUnknownFields: true}); jErr != nil {
+			return nil, jErr
+		}
+		if jErr := jModels.ValidatePassedArgs(reqBody.Params, &args); jErr != nil {
+			return nil, jErr
 		}
 		var res jModels.NilResult
 		err := s.NilResult(args, &res)
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil
 	case "AnotherPackageResult":
-		if reqBody.HasParams() {
-			return nil, jModels.NewError(jModels.ErrorCodeInvalidParams, "That method of service can't has param", nil)
+		var args Test1ContextArgs
+		if jErr := jModels.DecodeParams(reqBody.Params, &args, jModels.ParamsOptions{}); jErr != nil {
+			return nil, jErr
+		}
+		if jErr := jModels.ValidatePassedArgs(reqBody.Params, &args); jErr != nil {
+			return nil, jErr
 		}
-		var args jModels.NilArgs
 		args.WithContext(r.Context())
 		var res models.SomeModel
 		err := s.AnotherPackageResult(args, &res)
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil
 	case "DoubleStarAnotherResult":
@@ -61,7 +63,7 @@ func (s *Test1) Call(reqBody *jModels.RequestBody, r *http.Request) (interface{}
 		var res *models.SomeModel
 		err := s.DoubleStarAnotherResult(args, &res)
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil
 	case "DoubleStarResult":
@@ -72,10 +74,50 @@ func (s *Test1) Call(reqBody *jModels.RequestBody, r *http.Request) (interface{}
 		var res *Test1NilArgsResult
 		err := s.DoubleStarResult(args, &res)
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
+		}
+		return res, nil
+	case "WithContext":
+		var args Test1NilResultArgs
+		if jErr := jModels.DecodeParams(reqBody.Params, &args, jModels.ParamsOptions{}); jErr != nil {
+			return nil, jErr
+		}
+		if jErr := jModels.ValidatePassedArgs(reqBody.Params, &args); jErr != nil {
+			return nil, jErr
+		}
+		var res Test1NilArgsResult
+		err := s.WithContext(r.Context(), args, &res)
+		if err != nil {
+			return nil, jModels.NewErrorFromError(err)
+		}
+		return res, nil
+	case "ReturnResult":
+		var args Test1NilResultArgs
+		if jErr := jModels.DecodeParams(reqBody.Params, &args, jModels.ParamsOptions{}); jErr != nil {
+			return nil, jErr
+		}
+		if jErr := jModels.ValidatePassedArgs(reqBody.Params, &args); jErr != nil {
+			return nil, jErr
+		}
+		res, err := s.ReturnResult(r.Context(), args)
+		if err != nil {
+			return nil, jModels.NewErrorFromError(err)
+		}
+		return res, nil
+	case "ReturnPointerResult":
+		var args Test1NilResultArgs
+		if jErr := jModels.DecodeParams(reqBody.Params, &args, jModels.ParamsOptions{}); jErr != nil {
+			return nil, jErr
+		}
+		if jErr := jModels.ValidatePassedArgs(reqBody.Params, &args); jErr != nil {
+			return nil, jErr
+		}
+		res, err := s.ReturnPointerResult(r.Context(), &args)
+		if err != nil {
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil
 	default:
 		return nil, jModels.NewError(jModels.ErrorCodeMethodNotFound, fmt.Sprintf("Unknown method '%s' for service '%s'", reqBody.GetMethod(), "Test1"), nil)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,69 @@
+package testservice
+
+//------------------------------------------------------------------------------//
+// This file was generated by andrskom/jrpc2hh package. Please don't remove it. //
+//------------------------------------------------------------------------------//
+
+import (
+	context "context"
+	jClient "github.com/andrskom/jrpc2hh/client"
+	jModels "github.com/andrskom/jrpc2hh/models"
+	models "github.com/andrskom/jrpc2hh/testservice/models"
+)
+
+// Test1Client calls methods of service Test1 over http
+type Test1Client struct {
+	c *jClient.Client
+}
+
+func NewTest1Client(c *jClient.Client) *Test1Client {
+	return &Test1Client{c}
+}
+
+func (c *Test1Client) NilArgs(ctx context.Context) (Test1NilArgsResult, error) {
+	var res Test1NilArgsResult
+	err := c.c.Call(ctx, "Test1.NilArgs", nil, &res)
+	return res, err
+}
+
+func (c *Test1Client) NilResult(ctx context.Context, args Test1NilResultArgs) (jModels.NilResult, error) {
+	var res jModels.NilResult
+	err := c.c.Call(ctx, "Test1.NilResult", args, &res)
+	return res, err
+}
+
+func (c *Test1Client) AnotherPackageResult(ctx context.Context, args Test1ContextArgs) (models.SomeModel, error) {
+	var res models.SomeModel
+	err := c.c.Call(ctx, "Test1.AnotherPackageResult", args, &res)
+	return res, err
+}
+
+func (c *Test1Client) DoubleStarAnotherResult(ctx context.Context) (*models.SomeModel, error) {
+	var res *models.SomeModel
+	err := c.c.Call(ctx, "Test1.DoubleStarAnotherResult", nil, &res)
+	return res, err
+}
+
+func (c *Test1Client) DoubleStarResult(ctx context.Context) (*Test1NilArgsResult, error) {
+	var res *Test1NilArgsResult
+	err := c.c.Call(ctx, "Test1.DoubleStarResult", nil, &res)
+	return res, err
+}
+
+func (c *Test1Client) WithContext(ctx context.Context, args Test1NilResultArgs) (Test1NilArgsResult, error) {
+	var res Test1NilArgsResult
+	err := c.c.Call(ctx, "Test1.WithContext", args, &res)
+	return res, err
+}
+
+func (c *Test1Client) ReturnResult(ctx context.Context, args Test1NilResultArgs) (Test1NilArgsResult, error) {
+	var res Test1NilArgsResult
+	err := c.c.Call(ctx, "Test1.ReturnResult", args, &res)
+	return res, err
+}
+
+func (c *Test1Client) ReturnPointerResult(ctx context.Context, args *Test1NilResultArgs) (*models.SomeModel, error) {
+	var res *models.SomeModel
+	err := c.c.Call(ctx, "Test1.ReturnPointerResult", args, &res)
+	return res, err
+}
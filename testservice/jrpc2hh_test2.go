@@ -1,23 +1,22 @@
 package testservice
+
 //------------------------------------------------------------------------------//
 // This file was generated by andrskom/jrpc2hh package. Please don't remove it. //
 //------------------------------------------------------------------------------//
 
 import (
 	"fmt"
-	"net/http"
-	json "encoding/json"
 	jModels "github.com/andrskom/jrpc2hh/models"
-	models_7620940177658827552 "models"
-	models "some/models"
-	
+	models "github.com/andrskom/jrpc2hh/testservice/models"
+	models2 "github.com/andrskom/jrpc2hh/testservice/some/models"
+	"net/http"
 )
 
 // Call method for routing
 // nolint:gocyclo
 func (s *Test2) Call(reqBody *jModels.RequestBody, r *http.Request) (interface{}, *jModels.Error) {
 	switch reqBody.GetMethod() {
-	case "NilArgs":
+	case "nil_args", "NilArgs":
 		if reqBody.HasParams() {
 			return nil, jModels.NewError(jModels.ErrorCodeInvalidParams, "That method of service can't has param", nil)
 		}
@@ -25,35 +24,35 @@ func (s *Test2) Call(reqBody *jModels.RequestBody, r *http.Request) (interface{}
 		var res Test2NilArgsResult
 		err := s.NilArgs(args, &res)
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil
 	case "NilResult":
-		var args models_7620940177658827552.Test2NilResultArgs
-		if reqBody.HasParams() {
-			err := json.Unmarshal(*reqBody.Params, &args)
-			if err != nil {
-				return nil, jModels.NewError(jModels.ErrorCodeInvalidParams, "Can't unmarshal params to args structure'", err.Error())
-			}
+		var args models.Test2NilResultArgs
+		if jErr := jModels.DecodeParams(reqBody.Params, &args, jModels.ParamsOptions{RequiredFields: []string{"required_param"}}); jErr != nil {
+			return nil, jErr
+		}
+		if jErr := jModels.ValidatePassedArgs(reqBody.Params, &args); jErr != nil {
+			return nil, jErr
 		}
 		var res jModels.NilResult
 		err := s.NilResult(args, &res)
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil
 	case "AnotherPackageResult":
-		var args models.NilArgs
-		if reqBody.HasParams() {
-			err := json.Unmarshal(*reqBody.Params, &args)
-			if err != nil {
-				return nil, jModels.NewError(jModels.ErrorCodeInvalidParams, "Can't unmarshal params to args structure'", err.Error())
-			}
+		var args models2.NilArgs
+		if jErr := jModels.DecodeParams(reqBody.Params, &args, jModels.ParamsOptions{}); jErr != nil {
+			return nil, jErr
+		}
+		if jErr := jModels.ValidatePassedArgs(reqBody.Params, &args); jErr != nil {
+			return nil, jErr
 		}
 		var res models.SomeModel
 		err := s.AnotherPackageResult(args, &res)
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil
 	case "DoubleStarAnotherResult":
@@ -64,7 +63,7 @@ func (s *Test2) Call(reqBody *jModels.RequestBody, r *http.Request) (interface{}
 		var res *models.SomeModel
 		err := s.DoubleStarAnotherResult(args, &res)
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil
 	case "DoubleStarResult":
@@ -75,10 +74,15 @@ func (s *Test2) Call(reqBody *jModels.RequestBody, r *http.Request) (interface{}
 		var res *Test2NilArgsResult
 		err := s.DoubleStarResult(args, &res)
 		if err != nil {
-			return nil, jModels.NewError(jModels.ErrorCodeInternalError, "Internal error", err.Error())
+			return nil, jModels.NewErrorFromError(err)
 		}
 		return res, nil
 	default:
-		return nil, jModels.NewError(jModels.ErrorCodeMethodNotFound, fmt.Sprintf("Unknown method '%s' for service '%s'", reqBody.GetMethod(), "Test2"), nil)
+		return nil, jModels.NewError(jModels.ErrorCodeMethodNotFound, fmt.Sprintf("Unknown method '%s' for service '%s'", reqBody.GetMethod(), "test2"), nil)
 	}
-}
\ No newline at end of file
+}
+
+// ServiceName returns name of service used in requests
+func (s *Test2) ServiceName() string {
+	return "test2"
+}
@@ -0,0 +1,52 @@
+package testservice
+
+//------------------------------------------------------------------------------//
+// This file was generated by andrskom/jrpc2hh package. Please don't remove it. //
+//------------------------------------------------------------------------------//
+
+import (
+	context "context"
+	jClient "github.com/andrskom/jrpc2hh/client"
+	jModels "github.com/andrskom/jrpc2hh/models"
+	models "github.com/andrskom/jrpc2hh/testservice/models"
+	models2 "github.com/andrskom/jrpc2hh/testservice/some/models"
+)
+
+// Test2Client calls methods of service Test2 over http
+type Test2Client struct {
+	c *jClient.Client
+}
+
+func NewTest2Client(c *jClient.Client) *Test2Client {
+	return &Test2Client{c}
+}
+
+func (c *Test2Client) NilArgs(ctx context.Context) (Test2NilArgsResult, error) {
+	var res Test2NilArgsResult
+	err := c.c.Call(ctx, "test2.nil_args", nil, &res)
+	return res, err
+}
+
+func (c *Test2Client) NilResult(ctx context.Context, args models.Test2NilResultArgs) (jModels.NilResult, error) {
+	var res jModels.NilResult
+	err := c.c.Call(ctx, "test2.NilResult", args, &res)
+	return res, err
+}
+
+func (c *Test2Client) AnotherPackageResult(ctx context.Context, args models2.NilArgs) (models.SomeModel, error) {
+	var res models.SomeModel
+	err := c.c.Call(ctx, "test2.AnotherPackageResult", args, &res)
+	return res, err
+}
+
+func (c *Test2Client) DoubleStarAnotherResult(ctx context.Context) (*models.SomeModel, error) {
+	var res *models.SomeModel
+	err := c.c.Call(ctx, "test2.DoubleStarAnotherResult", nil, &res)
+	return res, err
+}
+
+func (c *Test2Client) DoubleStarResult(ctx context.Context) (*Test2NilArgsResult, error) {
+	var res *Test2NilArgsResult
+	err := c.c.Call(ctx, "test2.DoubleStarResult", nil, &res)
+	return res, err
+}
@@ -0,0 +1,10 @@
+package models
+
+type SomeModel struct {
+	SomeData string `json:"some_data"`
+}
+
+type Test2NilResultArgs struct {
+	RequiredParam string `json:"required_param"`
+	OptionalParam *int   `json:"optional_param"`
+}
@@ -0,0 +1,3 @@
+package models
+
+type NilArgs struct{}
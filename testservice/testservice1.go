@@ -1,9 +1,9 @@
 package testservice
 
 import (
+	"context"
 	jModels "github.com/andrskom/jrpc2hh/models"
-	"models"
-	"net"
+	"github.com/andrskom/jrpc2hh/testservice/models"
 )
 
 // jrpc2hh:service
@@ -25,17 +25,25 @@ func (s *Test1) NilArgs(args jModels.NilArgs, res *Test1NilArgsResult) error {
 }
 
 type Test1NilResultArgs struct {
-	RequiredParam string `json:"required_param"`
-	OptionalParam *int   `json:"optional_param"`
+	RequiredParam string `json:"required_param" jrpc2hh:"required,max=64"`
+	OptionalParam *int   `json:"optional_param" jrpc2hh:"min=0"`
 }
 
-// jrpc2hh:method
+// jrpc2hh:method params=required unknownFields=disallow
 func (s *Test1) NilResult(args Test1NilResultArgs, res *jModels.NilResult) error {
 	return nil
 }
 
+type Test1ContextArgs struct {
+	ctx context.Context
+}
+
+func (a *Test1ContextArgs) WithContext(ctx context.Context) {
+	a.ctx = ctx
+}
+
 // jrpc2hh:method:withContext
-func (s *Test1) AnotherPackageResult(args jModels.NilArgs, res *models.SomeModel) error {
+func (s *Test1) AnotherPackageResult(args Test1ContextArgs, res *models.SomeModel) error {
 	return nil
 }
 
@@ -48,3 +56,18 @@ func (s *Test1) DoubleStarAnotherResult(args jModels.NilArgs, res **models.SomeM
 func (s *Test1) DoubleStarResult(args jModels.NilArgs, res **Test1NilArgsResult) error {
 	return nil
 }
+
+// jrpc2hh:method
+func (s *Test1) WithContext(ctx context.Context, args Test1NilResultArgs, res *Test1NilArgsResult) error {
+	return nil
+}
+
+// jrpc2hh:method
+func (s *Test1) ReturnResult(ctx context.Context, args Test1NilResultArgs) (Test1NilArgsResult, error) {
+	return Test1NilArgsResult{}, nil
+}
+
+// jrpc2hh:method
+func (s *Test1) ReturnPointerResult(ctx context.Context, args *Test1NilResultArgs) (*models.SomeModel, error) {
+	return nil, nil
+}
@@ -2,12 +2,11 @@ package testservice
 
 import (
 	jModels "github.com/andrskom/jrpc2hh/models"
-	"models"
-	"net"
-	anotherModel "some/models"
+	"github.com/andrskom/jrpc2hh/testservice/models"
+	anotherModel "github.com/andrskom/jrpc2hh/testservice/some/models"
 )
 
-// jrpc2hh:service
+// jrpc2hh:service name=test2
 type Test2 struct {
 	db string // Test data
 }
@@ -20,7 +19,7 @@ type Test2NilArgsResult struct {
 	SomeData string `json:"some_data"`
 }
 
-// jrpc2hh:method
+// jrpc2hh:method name=nil_args alias=NilArgs
 func (s *Test2) NilArgs(args jModels.NilArgs, res *Test2NilArgsResult) error {
 	return nil
 }
@@ -30,7 +29,7 @@ type Test2NilResultArgs struct {
 	OptionalParam *int   `json:"optional_param"`
 }
 
-// jrpc2hh:method
+// jrpc2hh:method required=required_param
 func (s *Test2) NilResult(args models.Test2NilResultArgs, res *jModels.NilResult) error {
 	return nil
 }